@@ -0,0 +1,83 @@
+package api
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+)
+
+const (
+	riffHeaderSize  = 12
+	chunkHeaderSize = 8
+)
+
+var errInvalidRIFF = errors.New("invalid RIFF audio")
+
+// concatAudio joins audio returned by several synthesis requests into one stream of the given format.
+// Raw and compressed formats are simply appended, RIFF (WAV) outputs are merged into a single
+// container so that the result keeps one valid header.
+func concatAudio(output model.AudioOutput, chunks [][]byte) ([]byte, error) {
+	if len(chunks) == 1 {
+		return chunks[0], nil
+	}
+	if !strings.HasPrefix(output.String(), "riff-") {
+		return bytes.Join(chunks, nil), nil
+	}
+
+	var format []byte
+	var data bytes.Buffer
+	for i, chunk := range chunks {
+		fmtChunk, pcm, err := splitRIFF(chunk)
+		if err != nil {
+			return nil, fmt.Errorf("audio chunk %d: %w", i, err)
+		}
+		if format == nil {
+			format = fmtChunk
+		}
+		data.Write(pcm)
+	}
+
+	var out bytes.Buffer
+	out.WriteString("RIFF")
+	_ = binary.Write(&out, binary.LittleEndian, uint32(4+len(format)+chunkHeaderSize+data.Len()))
+	out.WriteString("WAVE")
+	out.Write(format)
+	out.WriteString("data")
+	_ = binary.Write(&out, binary.LittleEndian, uint32(data.Len()))
+	out.Write(data.Bytes())
+	return out.Bytes(), nil
+}
+
+// splitRIFF returns the complete "fmt " chunk (header included) and the payload of the "data" chunk of a WAV file.
+func splitRIFF(audio []byte) (format, data []byte, err error) {
+	if len(audio) < riffHeaderSize || string(audio[0:4]) != "RIFF" || string(audio[8:12]) != "WAVE" {
+		return nil, nil, errInvalidRIFF
+	}
+	pos := riffHeaderSize
+	for pos+chunkHeaderSize <= len(audio) {
+		id := string(audio[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(audio[pos+4 : pos+8]))
+		start := pos + chunkHeaderSize
+		end := start + size
+		if end > len(audio) || end < start {
+			// streamed outputs may carry a placeholder size, take everything that is left
+			end = len(audio)
+		}
+		switch id {
+		case "fmt ":
+			format = audio[pos:end]
+		case "data":
+			data = audio[start:end]
+		}
+		// chunks are word aligned
+		pos = end + size%2
+	}
+	if format == nil || data == nil {
+		return nil, nil, errInvalidRIFF
+	}
+	return format, data, nil
+}
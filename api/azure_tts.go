@@ -1,16 +1,67 @@
 package api
 
 import (
+	"log/slog"
 	"net/http"
+	"sync"
+	"time"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+	"github.com/barkingdog-ai/azure-tts/usage"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type AzureTTSClient struct {
-	HTTPClient          *http.Client
-	AccessToken         string
-	SubscriptionKey     string
+	HTTPClient      *http.Client
+	AccessToken     string
+	SubscriptionKey string
+	// Deprecated: call Close instead of closing the channel.
 	TokenRefreshDoneCh  chan bool
 	TokenRefreshURL     string
 	VoiceServiceListURL string
 	TextToSpeechURL     string
 	SpeechToTextURL     string
+	// Region is the region of the Speech resource, reported in telemetry and errors. It is set by
+	// NewClient and is independent of the endpoint URLs, which may point elsewhere.
+	Region model.Region
+
+	// voice catalog cached for synthesis-time style resolution, see voiceCatalog
+	voicesMu      sync.Mutex
+	voices        []model.VoiceListResponse
+	voicesFetched time.Time
+	voicesTTL     time.Duration
+	voicesFetch   chan struct{} // closed when the fetch in flight, if any, ends
+	voicesErr     error
+	voicesFailed  time.Time
+
+	// request policies set up by WithRetry, WithRateLimit and WithLexicons
+	retry    *retryPolicy
+	limiter  *rateLimiter
+	lexicons []string
+
+	// instruments set up by WithTracerProvider and WithMeterProvider
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	tel            *telemetry
+
+	// structured logging set up by WithLogger
+	logger        *slog.Logger
+	logSpeechText bool
+
+	// usage accounting set up by WithUsageMeter
+	usageMeter usage.Meter
+
+	// token management, see TokenSource and WithLazyAuth
+	tokenMu        sync.Mutex
+	tokenExpiry    time.Time
+	tokenSource    TokenSource
+	lazyAuth       bool
+	tokenRefreshMu sync.Mutex // serializes the refreshes of bearerToken
+
+	// lifecycle, see Close
+	lifeMu   sync.Mutex
+	closed   bool
+	done     chan struct{}
+	inflight sync.WaitGroup
 }
@@ -1,8 +1,15 @@
 package api
 
 import (
+	"fmt"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"time"
+
+	"github.com/barkingdog-ai/azure-tts/usage"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type ClientOption func(*AzureTTSClient) error
@@ -23,3 +30,138 @@ func WithTimeout(timeout time.Duration) ClientOption {
 		return nil
 	}
 }
+
+// WithTracerProvider traces every call to Azure (RefreshToken, VoiceList, TextToSpeech and SpeechToText)
+// with a span from tp. Calls are not traced by default.
+func WithTracerProvider(tp trace.TracerProvider) ClientOption {
+	return func(c *AzureTTSClient) error {
+		tel, err := newTelemetry(tp, c.meterProvider)
+		if err != nil {
+			return err
+		}
+		c.tracerProvider, c.tel = tp, tel
+		return nil
+	}
+}
+
+// WithMeterProvider records latency, time to first byte, characters, audio bytes and errors of the
+// calls to Azure with instruments from mp. No metrics are recorded by default.
+func WithMeterProvider(mp metric.MeterProvider) ClientOption {
+	return func(c *AzureTTSClient) error {
+		tel, err := newTelemetry(c.tracerProvider, mp)
+		if err != nil {
+			return err
+		}
+		c.meterProvider, c.tel = mp, tel
+		return nil
+	}
+}
+
+// WithLogger writes structured events to logger: token refreshes, request and response summaries and
+// voice catalog cache decisions. Subscription keys and bearer tokens are redacted, and so is the
+// speech text unless WithSpeechTextLogging is enabled.
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(c *AzureTTSClient) error {
+		c.logger = logger
+		return nil
+	}
+}
+
+// WithSpeechTextLogging includes the text being synthesized in log events when enabled. It is
+// redacted by default because it may contain personal data.
+func WithSpeechTextLogging(enabled bool) ClientOption {
+	return func(c *AzureTTSClient) error {
+		c.logSpeechText = enabled
+		return nil
+	}
+}
+
+// WithUsageMeter reports the billable characters of every synthesis and the recognized audio of every
+// recognition to m, accounted to the tenant set on the context with usage.WithTenant.
+func WithUsageMeter(m usage.Meter) ClientOption {
+	return func(c *AzureTTSClient) error {
+		c.usageMeter = m
+		return nil
+	}
+}
+
+// WithLazyAuth skips fetching a token while the client is built, so it can be constructed without
+// network access. The token is fetched on first use instead.
+func WithLazyAuth() ClientOption {
+	return func(c *AzureTTSClient) error {
+		c.lazyAuth = true
+		return nil
+	}
+}
+
+// WithTokenSource authorizes requests with tokens from ts instead of exchanging the subscription key.
+// The client then neither fetches a token while being built nor refreshes it in the background.
+func WithTokenSource(ts TokenSource) ClientOption {
+	return func(c *AzureTTSClient) error {
+		c.tokenSource = ts
+		return nil
+	}
+}
+
+// WithStaticToken authorizes every request with token, for environments where tokens are minted
+// and rotated elsewhere.
+func WithStaticToken(token string) ClientOption {
+	return func(c *AzureTTSClient) error {
+		c.tokenSource = StaticToken(token)
+		c.AccessToken = token
+		return nil
+	}
+}
+
+// WithRetry sends a request up to maxAttempts times while it fails with a network error, is throttled
+// or the service is unavailable (500, 502, 503 or 504). Attempts wait for the Retry-After of the
+// response, or else backoff doubled after every attempt. Requests are not retried by default.
+func WithRetry(maxAttempts int, backoff time.Duration) ClientOption {
+	return func(c *AzureTTSClient) error {
+		if maxAttempts < 1 || backoff < 0 {
+			return fmt.Errorf("invalid retry policy: %d attempts, backoff %s", maxAttempts, backoff)
+		}
+		c.retry = &retryPolicy{maxAttempts: maxAttempts, backoff: backoff}
+		return nil
+	}
+}
+
+// WithRateLimit limits the requests of the client to requestsPerSecond, allowing bursts of burst
+// requests. Requests over the limit wait for their turn. There is no limit by default.
+func WithRateLimit(requestsPerSecond float64, burst int) ClientOption {
+	return func(c *AzureTTSClient) error {
+		if requestsPerSecond <= 0 {
+			return fmt.Errorf("invalid rate limit: %g requests per second", requestsPerSecond)
+		}
+		c.limiter = newRateLimiter(requestsPerSecond, burst)
+		return nil
+	}
+}
+
+// WithVoiceCatalogTTL sets how long the voice list fetched for style resolution and voice selection
+// is reused, 24 hours by default.
+func WithVoiceCatalogTTL(ttl time.Duration) ClientOption {
+	return func(c *AzureTTSClient) error {
+		if ttl <= 0 {
+			return fmt.Errorf("invalid voice catalog TTL: %s", ttl)
+		}
+		c.voicesTTL = ttl
+		return nil
+	}
+}
+
+// WithLexicons references the custom lexicons at uris in every voice of the synthesized SSML. The
+// files must be reachable by the service, e.g. in a public blob container.
+// See: https://learn.microsoft.com/en-us/azure/ai-services/speech-service/speech-synthesis-markup-pronunciation#custom-lexicon
+func WithLexicons(uris ...string) ClientOption {
+	return func(c *AzureTTSClient) error {
+		for _, uri := range uris {
+			u, err := url.Parse(uri)
+			if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+				return fmt.Errorf("invalid lexicon URI %q: an http or https URL is required", uri)
+			}
+		}
+		c.lexicons = append(c.lexicons, uris...)
+		return nil
+	}
+}
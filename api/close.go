@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"errors"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+)
+
+// flusher is implemented by the OpenTelemetry SDK providers and by buffering usage meters.
+type flusher interface {
+	ForceFlush(ctx context.Context) error
+}
+
+// begin registers an in-flight call, failing with model.ErrClientClosed once Close was called.
+// Every successful begin must be paired with a call to az.inflight.Done.
+func (az *AzureTTSClient) begin() error {
+	az.lifeMu.Lock()
+	defer az.lifeMu.Unlock()
+	if az.closed {
+		return model.ErrClientClosed
+	}
+	az.inflight.Add(1)
+	return nil
+}
+
+// Done returns a channel that is closed when Close is called, e.g. to stop background work
+// like the token refresher.
+func (az *AzureTTSClient) Done() <-chan struct{} {
+	az.lifeMu.Lock()
+	defer az.lifeMu.Unlock()
+	return az.doneChan()
+}
+
+// doneChan returns the channel closed by Close, creating it on first use. az.lifeMu must be held.
+func (az *AzureTTSClient) doneChan() chan struct{} {
+	if az.done == nil {
+		az.done = make(chan struct{})
+	}
+	return az.done
+}
+
+// Close stops the token refresher and rejects new calls with model.ErrClientClosed. It then waits
+// for in-flight calls, including open TextToSpeechStream readers, to finish or ctx to expire,
+// drops the cached voice catalog and flushes the tracer, meter and usage meter when they support
+// it. Calling Close again only waits for in-flight calls.
+func (az *AzureTTSClient) Close(ctx context.Context) error {
+	az.lifeMu.Lock()
+	if !az.closed {
+		az.closed = true
+		close(az.doneChan())
+	}
+	az.lifeMu.Unlock()
+
+	idle := make(chan struct{})
+	go func() {
+		az.inflight.Wait()
+		close(idle)
+	}()
+	select {
+	case <-idle:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	az.voicesMu.Lock()
+	az.voices = nil
+	az.voicesErr = nil
+	az.voicesMu.Unlock()
+
+	var errs []error
+	for _, f := range []any{az.tracerProvider, az.meterProvider, az.usageMeter} {
+		if f, ok := f.(flusher); ok {
+			errs = append(errs, f.ForceFlush(ctx))
+		}
+	}
+	return errors.Join(errs...)
+}
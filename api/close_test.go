@@ -0,0 +1,49 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClose(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("audio"))
+	}))
+	defer srv.Close()
+
+	az := &AzureTTSClient{HTTPClient: srv.Client(), TextToSpeechURL: srv.URL, voices: []model.VoiceListResponse{{ShortName: "a"}}}
+	request := &model.TextToSpeechRequest{SpeechText: "你好", VoiceName: "zh-TW-HsiaoChenNeural"}
+
+	stream, err := az.TextToSpeechStream(context.Background(), request)
+	assert.NoError(t, err)
+
+	// the open stream keeps Close waiting
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, az.Close(ctx), context.DeadlineExceeded)
+
+	select {
+	case <-az.Done():
+	default:
+		t.Fatal("Done is not closed")
+	}
+	_, err = az.TextToSpeech(context.Background(), request)
+	assert.ErrorIs(t, err, model.ErrClientClosed)
+	_, err = az.SpeechToText(context.Background(), model.SpeechToTextReq{Reader: http.NoBody})
+	assert.ErrorIs(t, err, model.ErrClientClosed)
+
+	audio, err := io.ReadAll(stream)
+	assert.NoError(t, err)
+	assert.Equal(t, "audio", string(audio))
+	assert.NoError(t, stream.Close())
+
+	assert.NoError(t, az.Close(context.Background()))
+	assert.Nil(t, az.voices)
+}
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+	"github.com/barkingdog-ai/azure-tts/usage"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// maxVoicesPerRequest is the number of <voice> elements the service accepts in a single SSML document.
+// See: https://learn.microsoft.com/en-us/azure/ai-services/speech-service/speech-services-quotas-and-limits#text-to-speech-quotas-and-limits-per-resource
+const maxVoicesPerRequest = 50
+
+// maxBreakDuration is the longest pause a single <break> element may request.
+const maxBreakDuration = 5 * time.Second
+
+type DialogueInterface interface {
+	Dialogue(ctx context.Context, req *model.DialogueRequest) ([]byte, error)
+}
+
+// Dialogue synthesizes a conversation between several voices into a single audio stream.
+// Turns are rendered as consecutive <voice> elements; when the dialogue has more turns than
+// the service accepts in one request it is split into several requests and the audio is concatenated.
+func (az *AzureTTSClient) Dialogue(ctx context.Context, request *model.DialogueRequest) ([]byte, error) {
+	if err := az.begin(); err != nil {
+		return nil, err
+	}
+	defer az.inflight.Done()
+
+	turns, err := resolveDialogueTurns(request)
+	if err != nil {
+		return nil, err
+	}
+
+	documents := dialogueXML(turns)
+	chunks := make([][]byte, 0, len(documents))
+	for i, doc := range documents {
+		audio, err := az.synthesizeSSML(ctx, doc, request.AudioOutput)
+		if err != nil {
+			return nil, fmt.Errorf("dialogue part %d: %w", i, err)
+		}
+		chunks = append(chunks, audio)
+	}
+	return concatAudio(request.AudioOutput, chunks)
+}
+
+// synthesizeSSML posts a prepared SSML document to the TTS endpoint and returns the audio.
+func (az *AzureTTSClient) synthesizeSSML(ctx context.Context, ssml string, audioOutput model.AudioOutput) (_ []byte, err error) {
+	ctx, c := az.startCall(ctx, "TextToSpeech", attrOutputFormat.String(audioOutput.String()))
+	defer func() { c.end(ctx, err) }()
+
+	ssml = az.withLexicons(ssml)
+
+	req, err := az.newTTSRequest(ctx, "POST", az.TextToSpeechURL, bytes.NewBufferString(ssml), audioOutput)
+	if err != nil {
+		return nil, fmt.Errorf("tts request error %w", err)
+	}
+
+	resp, err := az.performRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("perform request error %w", invalidSSML(err))
+	}
+	defer resp.Body.Close()
+
+	c.span.SetAttributes(attrCharacters.Int(usage.BillableCharacters(ssml)))
+	for _, group := range voiceCharacters(ssml) {
+		c.tel.characters.Add(ctx, int64(group.characters), metric.WithAttributes(c.with(attrVoice.String(group.voice))...))
+		az.recordUsage(ctx, usage.Record{Kind: usage.KindForVoice(group.voice), Voice: group.voice, Characters: group.characters})
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// reVoiceElement matches a <voice> element of a dialogue document and captures its name and content.
+var reVoiceElement = regexp.MustCompile(`(?s)<voice name="([^"]*)">(.*?)</voice>`)
+
+// voiceGroup is the billable characters spoken by one voice of a document.
+type voiceGroup struct {
+	voice      string
+	characters int
+}
+
+// voiceCharacters returns the billable characters of every voice of a dialogue document, in the order
+// the voices first speak.
+func voiceCharacters(ssml string) []voiceGroup {
+	var groups []voiceGroup
+	index := map[string]int{}
+	for _, m := range reVoiceElement.FindAllStringSubmatch(ssml, -1) {
+		voice := html.UnescapeString(m[1])
+		i, ok := index[voice]
+		if !ok {
+			i = len(groups)
+			index[voice] = i
+			groups = append(groups, voiceGroup{voice: voice})
+		}
+		groups[i].characters += usage.BillableCharacters(m[2])
+	}
+	return groups
+}
+
+// resolveDialogueTurns fills every turn with the defaults of its speaker and validates the result.
+func resolveDialogueTurns(request *model.DialogueRequest) ([]model.DialogueTurn, error) {
+	if len(request.Turns) == 0 {
+		return nil, fmt.Errorf("dialogue has no turns")
+	}
+	turns := make([]model.DialogueTurn, 0, len(request.Turns))
+	for i, turn := range request.Turns {
+		if speaker, ok := request.Speakers[turn.Speaker]; ok {
+			if turn.VoiceName == "" {
+				turn.VoiceName = speaker.VoiceName
+			}
+			if turn.Locale == "" {
+				turn.Locale = speaker.Locale
+			}
+			if turn.Style == nil {
+				turn.Style = speaker.Style
+			}
+			if turn.Role == "" {
+				turn.Role = speaker.Role
+			}
+		}
+		if turn.VoiceName == "" {
+			return nil, fmt.Errorf("dialogue turn %d (%s): voice name is required", i, turn.Speaker)
+		}
+		if strings.TrimSpace(turn.Text) == "" && turn.PauseAfter <= 0 {
+			return nil, fmt.Errorf("dialogue turn %d (%s): text is empty", i, turn.Speaker)
+		}
+		turn.Text = correctHomophones(turn.Text, request.Homophones)
+		turns = append(turns, turn)
+	}
+	return turns, nil
+}
+
+// dialogueXML renders the turns as SSML documents holding at most maxVoicesPerRequest voices each.
+func dialogueXML(turns []model.DialogueTurn) []string {
+	documents := make([]string, 0, (len(turns)+maxVoicesPerRequest-1)/maxVoicesPerRequest)
+	for start := 0; start < len(turns); start += maxVoicesPerRequest {
+		end := start + maxVoicesPerRequest
+		if end > len(turns) {
+			end = len(turns)
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, ssmlSpeakOpen, documentLocale(turns[start:end]))
+		for _, turn := range turns[start:end] {
+			writeDialogueTurn(&b, turn)
+		}
+		b.WriteString(`</speak>`)
+		documents = append(documents, b.String())
+	}
+	return documents
+}
+
+func documentLocale(turns []model.DialogueTurn) model.Locale {
+	for _, turn := range turns {
+		if turn.Locale != "" {
+			return turn.Locale
+		}
+	}
+	return model.LocaleEnUS
+}
+
+func writeDialogueTurn(b *strings.Builder, turn model.DialogueTurn) {
+	fmt.Fprintf(b, `<voice name="%s">`, xmlEscaper.Replace(turn.VoiceName))
+	if text := processSpeechText(turn.Text, turn.Locale); text != "" {
+		if turn.Style != nil || turn.Role != "" {
+			style := model.TTSStyle{Role: turn.Role}
+			if turn.Style != nil {
+				style.Style = turn.Style.Style
+				style.StyleDegree = turn.Style.StyleDegree
+				if style.Role == "" {
+					style.Role = turn.Style.Role
+				}
+			}
+			b.WriteString(expressAsOpen(&style))
+			b.WriteString(text)
+			b.WriteString(`</mstts:express-as>`)
+		} else {
+			b.WriteString(text)
+		}
+	}
+	for pause := turn.PauseAfter; pause > 0; pause -= maxBreakDuration {
+		d := pause
+		if d > maxBreakDuration {
+			d = maxBreakDuration
+		}
+		fmt.Fprintf(b, `<break time="%dms"/>`, d.Milliseconds())
+	}
+	b.WriteString(`</voice>`)
+}
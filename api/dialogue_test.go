@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+	"github.com/barkingdog-ai/azure-tts/usage"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_dialogueXML(t *testing.T) {
+	request := &model.DialogueRequest{
+		Speakers: map[string]model.DialogueSpeaker{
+			"agent":    {VoiceName: "zh-TW-HsiaoChenNeural", Locale: model.LocaleZhTW, Style: &model.TTSStyle{Style: "friendly", StyleDegree: "1"}},
+			"customer": {VoiceName: "zh-TW-YunJheNeural", Locale: model.LocaleZhTW, Role: "OlderAdultMale"},
+		},
+		Turns: []model.DialogueTurn{
+			{Speaker: "agent", Text: "您好，請問需要什麼協助？", PauseAfter: 500 * time.Millisecond},
+			{Speaker: "customer", Text: "我的訂單還沒到。"},
+			{Speaker: "agent", VoiceName: "zh-TW-HsiaoYuNeural", Style: &model.TTSStyle{Style: "calm"}, Text: "我馬上幫您查詢。", PauseAfter: 7 * time.Second},
+		},
+	}
+
+	turns, err := resolveDialogueTurns(request)
+	assert.NoError(t, err)
+
+	want := `<speak version="1.0" xmlns="http://www.w3.org/2001/10/synthesis" xmlns:mstts="https://www.w3.org/2001/mstts" xml:lang="zh-TW">` +
+		`<voice name="zh-TW-HsiaoChenNeural"><mstts:express-as style="friendly" styledegree="1">您好，請問需要什麼協助？</mstts:express-as><break time="500ms"/></voice>` +
+		`<voice name="zh-TW-YunJheNeural"><mstts:express-as role="OlderAdultMale">我的訂單還沒到。</mstts:express-as></voice>` +
+		`<voice name="zh-TW-HsiaoYuNeural"><mstts:express-as style="calm">我馬上幫您查詢。</mstts:express-as><break time="5000ms"/><break time="2000ms"/></voice>` +
+		`</speak>`
+	assert.Equal(t, []string{want}, dialogueXML(turns))
+}
+
+func Test_dialogueXMLSplitsOnVoiceLimit(t *testing.T) {
+	turns := make([]model.DialogueTurn, maxVoicesPerRequest*2+1)
+	for i := range turns {
+		turns[i] = model.DialogueTurn{VoiceName: "en-US-JennyNeural", Locale: model.LocaleEnUS, Text: fmt.Sprint("line ", i)}
+	}
+
+	documents := dialogueXML(turns)
+	assert.Len(t, documents, 3)
+	for i, n := range []int{maxVoicesPerRequest, maxVoicesPerRequest, 1} {
+		assert.Equal(t, n, strings.Count(documents[i], "<voice "))
+	}
+}
+
+func Test_resolveDialogueTurns(t *testing.T) {
+	_, err := resolveDialogueTurns(&model.DialogueRequest{})
+	assert.Error(t, err)
+
+	_, err = resolveDialogueTurns(&model.DialogueRequest{Turns: []model.DialogueTurn{{Speaker: "unknown", Text: "hi"}}})
+	assert.Error(t, err)
+}
+
+func wav(pcm ...byte) []byte {
+	format := []byte{1, 0, 1, 0, 0x40, 0x1f, 0, 0, 0x80, 0x3e, 0, 0, 2, 0, 16, 0}
+	out := []byte("RIFF")
+	out = binary.LittleEndian.AppendUint32(out, uint32(4+8+len(format)+8+len(pcm)))
+	out = append(out, "WAVEfmt "...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(format)))
+	out = append(out, format...)
+	out = append(out, "data"...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(pcm)))
+	return append(out, pcm...)
+}
+
+func Test_concatAudio(t *testing.T) {
+	got, err := concatAudio(model.AudioRIFF16Bit16kHzMonoPCM, [][]byte{wav(1, 2), wav(3, 4, 5, 6)})
+	assert.NoError(t, err)
+	assert.Equal(t, wav(1, 2, 3, 4, 5, 6), got)
+
+	got, err = concatAudio(model.Audio16khz32kbitrateMonoMp3, [][]byte{{1, 2}, {3}})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{1, 2, 3}, got)
+
+	_, err = concatAudio(model.AudioRIFF16Bit16kHzMonoPCM, [][]byte{wav(1), []byte("not a wav")})
+	assert.Error(t, err)
+}
+
+func Test_dialogueXMLEscapesAttributes(t *testing.T) {
+	turns := []model.DialogueTurn{{VoiceName: `a" name="b`, Role: `x"`, Style: &model.TTSStyle{Style: "<sad>"}, Text: "hi"}}
+	documents := dialogueXML(turns)
+	assert.Contains(t, documents[0], `<voice name="a&quot; name=&quot;b"><mstts:express-as role="x&quot;" style="&lt;sad&gt;">hi`)
+	assert.NoError(t, xml.Unmarshal([]byte(documents[0]), new(struct{})))
+}
+
+func Test_voiceCharacters(t *testing.T) {
+	turns := []model.DialogueTurn{
+		{VoiceName: "en-US-JennyNeural", Text: "hello"},
+		{VoiceName: "en-US-JessaRUS", Text: "hi"},
+		{VoiceName: "en-US-JennyNeural", Text: "bye"},
+	}
+	groups := voiceCharacters(dialogueXML(turns)[0])
+	assert.Equal(t, []voiceGroup{{voice: "en-US-JennyNeural", characters: 8}, {voice: "en-US-JessaRUS", characters: 2}}, groups)
+	assert.Equal(t, usage.KindStandardTTS, usage.KindForVoice(groups[1].voice))
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypedErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         int
+		header         map[string]string
+		body           string
+		want           error
+		wantType       string
+		wantRetryAfter time.Duration
+	}{
+		{name: "unauthorized", status: http.StatusUnauthorized, want: model.ErrUnauthorized, wantType: "Unexpected"},
+		{
+			name: "throttled", status: http.StatusTooManyRequests, header: map[string]string{"Retry-After": "7"},
+			body: `{"error":{"type":"Throttled","message":"slow down"}}`, want: model.ErrThrottled, wantType: "Throttled", wantRetryAfter: 7 * time.Second,
+		},
+		{name: "too large", status: http.StatusRequestEntityTooLarge, want: model.ErrPayloadTooLarge, wantType: "Unexpected"},
+		{name: "bad ssml", status: http.StatusBadRequest, want: model.ErrInvalidSSML, wantType: "Unexpected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-RequestId", "req-42")
+				for k, v := range tt.header {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			az := &AzureTTSClient{HTTPClient: srv.Client(), TextToSpeechURL: srv.URL, Region: model.RegionEastAsia}
+			_, err := az.TextToSpeech(context.Background(), &model.TextToSpeechRequest{SpeechText: "你好", VoiceName: "zh-TW-HsiaoChenNeural"})
+			assert.ErrorIs(t, err, tt.want)
+
+			var apiErr model.APIError
+			assert.True(t, errors.As(err, &apiErr))
+			assert.Equal(t, tt.status, apiErr.StatusCode)
+			assert.Equal(t, tt.wantType, apiErr.Type)
+			assert.Equal(t, "req-42", apiErr.RequestID)
+			assert.Equal(t, "eastasia", apiErr.Region)
+			retryAfter, ok := model.RetryAfter(err)
+			assert.Equal(t, tt.wantRetryAfter, retryAfter)
+			assert.Equal(t, tt.wantRetryAfter > 0, ok)
+		})
+	}
+}
+
+func TestNetworkAndRecognitionErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("language") == "slow" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		_, _ = w.Write([]byte(`{"RecognitionStatus":"NoMatch","Offset":0,"Duration":12000000}`))
+	}))
+	defer srv.Close()
+
+	client := srv.Client()
+	client.Timeout = 10 * time.Millisecond
+	az := &AzureTTSClient{HTTPClient: client, SpeechToTextURL: srv.URL}
+
+	_, err := az.SpeechToText(context.Background(), model.SpeechToTextReq{Reader: http.NoBody, Language: "slow"})
+	assert.ErrorIs(t, err, model.ErrNetwork)
+	assert.ErrorIs(t, err, model.ErrTimeout)
+
+	client.Timeout = time.Second
+	resp, err := az.SpeechToText(context.Background(), model.SpeechToTextReq{Reader: http.NoBody, Language: "zh-TW"})
+	assert.ErrorIs(t, err, model.ErrNoMatch)
+	assert.Equal(t, 12000000, resp.Duration)
+}
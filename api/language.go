@@ -0,0 +1,48 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+)
+
+// spanPlaceholder stands in for an SSML element or entity of the text while it is split into language
+// runs. It belongs to no script, so the span stays whole in the run around it.
+const spanPlaceholder = '\uE000'
+
+// languageSegments splits text into its language runs. Runs in locale are spoken by voice as they are,
+// runs in another locale by the voice languages assigns to it or, without one, by voice in a <lang>
+// element. render renders the text of a run for its locale. SSML elements and entities already in text,
+// as matched by reMarkupSpan, are never split or detected as a language.
+func languageSegments(text, voice string, locale model.Locale, languages *model.LanguageDetection,
+	render func(text string, locale model.Locale) string,
+) []ssmlSegment {
+	spans := reMarkupSpan.FindAllString(text, -1)
+	masked := reMarkupSpan.ReplaceAllLiteralString(text, string(spanPlaceholder))
+	runs := languages.Runs(masked, locale)
+	if len(runs) == 0 {
+		return []ssmlSegment{{}}
+	}
+	segments := make([]ssmlSegment, 0, len(runs))
+	for _, run := range runs {
+		var body strings.Builder
+		for _, r := range run.Text {
+			if r == spanPlaceholder && len(spans) > 0 {
+				body.WriteString(spans[0])
+				spans = spans[1:]
+				continue
+			}
+			body.WriteRune(r)
+		}
+		segment := ssmlSegment{body: render(body.String(), run.Locale)}
+		if run.Locale != locale {
+			if other := languages.Voices[run.Locale]; other != "" && other != voice {
+				segment.voice = other
+			} else {
+				segment.lang = run.Locale
+			}
+		}
+		segments = append(segments, segment)
+	}
+	return segments
+}
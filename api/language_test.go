@@ -0,0 +1,87 @@
+package api
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_languageXML(t *testing.T) {
+	const speak = `<speak version="1.0" xmlns="http://www.w3.org/2001/10/synthesis" xmlns:mstts="https://www.w3.org/2001/mstts" xml:lang="zh-TW">`
+	tests := []struct {
+		name      string
+		text      string
+		languages *model.LanguageDetection
+		style     *model.TTSStyle
+		markup    bool
+		want      string
+	}{
+		{
+			name:      "lang elements for a multilingual voice",
+			text:      "請登入你的 Dashboard 查看 invoice",
+			languages: &model.LanguageDetection{},
+			want: speak + `<voice name="zh-TW-Multilingual">請登入你的 <lang xml:lang="en-US">Dashboard </lang>查看 ` +
+				`<lang xml:lang="en-US">invoice</lang></voice></speak>`,
+		},
+		{
+			name:      "secondary voice without the request style",
+			text:      "請登入你的 Dashboard 查看",
+			languages: &model.LanguageDetection{Voices: map[model.Locale]string{model.LocaleEnUS: "en-US-AvaNeural"}},
+			style:     &model.TTSStyle{Style: "cheerful"},
+			want: speak + `<voice name="zh-TW-Multilingual"><mstts:express-as style="cheerful">請登入你的 </mstts:express-as></voice>` +
+				`<voice name="en-US-AvaNeural">Dashboard </voice>` +
+				`<voice name="zh-TW-Multilingual"><mstts:express-as style="cheerful">查看</mstts:express-as></voice></speak>`,
+		},
+		{
+			name:      "request voice listed for a language",
+			text:      "查看 invoice",
+			languages: &model.LanguageDetection{Voices: map[model.Locale]string{model.LocaleEnUS: "zh-TW-Multilingual"}},
+			want:      speak + `<voice name="zh-TW-Multilingual">查看 <lang xml:lang="en-US">invoice</lang></voice></speak>`,
+		},
+		{
+			name:      "single language stays plain",
+			text:      "你好",
+			languages: &model.LanguageDetection{},
+			want:      `<speak version='1.0' xml:lang='zh-TW'><voice xml:lang='zh-TW' xml:gender='Female' name='zh-TW-Multilingual'>你好</voice></speak>`,
+		},
+		{
+			name:      "ssml elements in the text",
+			text:      `你好<break time="500ms"/>世界 &amp; <say-as interpret-as="characters">ABC</say-as>`,
+			languages: &model.LanguageDetection{},
+			want: `<speak version='1.0' xml:lang='zh-TW'><voice xml:lang='zh-TW' xml:gender='Female' name='zh-TW-Multilingual'>` +
+				`你好<break time="500ms"/>世界 &amp; <say-as interpret-as="characters">ABC</say-as></voice></speak>`,
+		},
+		{
+			name:      "amount with a currency prefix",
+			text:      "價格 NT$1,200 元",
+			languages: &model.LanguageDetection{},
+			want: `<speak version='1.0' xml:lang='zh-TW'><voice xml:lang='zh-TW' xml:gender='Female' name='zh-TW-Multilingual'>` +
+				`價格 <say-as interpret-as="currency">1200 TWD</say-as> 元</voice></speak>`,
+		},
+		{
+			name:      "top-level markup text",
+			text:      "請看 [[emphasis|Dashboard]] & Q&A",
+			languages: &model.LanguageDetection{},
+			markup:    true,
+			want: speak + `<voice name="zh-TW-Multilingual">請看 <emphasis level="moderate">Dashboard</emphasis>` +
+				`<lang xml:lang="en-US"> &amp; Q&amp;A</lang></voice></speak>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			if tt.markup {
+				var err error
+				got, err = markupXML(tt.text, "zh-TW-Multilingual", model.LocaleZhTW, model.GenderFemale, nil, tt.style, tt.languages)
+				require.NoError(t, err)
+			} else {
+				got = voiceXML(tt.text, "zh-TW-Multilingual", model.LocaleZhTW, model.GenderFemale, nil, tt.style, tt.languages)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, xml.Unmarshal([]byte(got), new(struct{})))
+		})
+	}
+}
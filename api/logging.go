@@ -0,0 +1,84 @@
+package api
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// redacted replaces secrets in log output.
+const redacted = "[REDACTED]"
+
+// discardLogger is used until WithLogger is given.
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// Logger returns the logger of the client. It discards everything unless WithLogger was given.
+func (az *AzureTTSClient) Logger() *slog.Logger {
+	if az.logger == nil {
+		return discardLogger
+	}
+	return az.logger
+}
+
+// redactHeaders returns a copy of h safe to log: the subscription key is hidden and bearer tokens
+// keep only their scheme.
+func redactHeaders(h http.Header) http.Header {
+	out := h.Clone()
+	for name, values := range out {
+		switch http.CanonicalHeaderKey(name) {
+		case "Ocp-Apim-Subscription-Key":
+			out[name] = []string{redacted}
+		case "Authorization":
+			for i, v := range values {
+				scheme, _, _ := strings.Cut(v, " ")
+				values[i] = scheme + " " + redacted
+			}
+		}
+	}
+	return out
+}
+
+// redactURL returns u without its query string, which may carry tokens or text.
+func redactURL(u string) string {
+	base, _, _ := strings.Cut(u, "?")
+	return base
+}
+
+// speechTextAttr logs the number of characters of text, and the text itself only when
+// WithSpeechTextLogging was enabled.
+func (az *AzureTTSClient) speechTextAttr(text string) slog.Attr {
+	if !az.logSpeechText {
+		return slog.Group("text", slog.Int("characters", len([]rune(text))), slog.String("content", redacted))
+	}
+	return slog.Group("text", slog.Int("characters", len([]rune(text))), slog.String("content", text))
+}
+
+// logRequest writes the summary of req before it is sent.
+func (az *AzureTTSClient) logRequest(ctx context.Context, req *http.Request) {
+	az.Logger().LogAttrs(ctx, slog.LevelDebug, "azure request",
+		slog.String("method", req.Method),
+		slog.String("url", redactURL(req.URL.String())),
+		slog.Any("headers", redactHeaders(req.Header)))
+}
+
+// logResponse writes the summary of a response to req, or of the error that prevented one.
+func (az *AzureTTSClient) logResponse(ctx context.Context, req *http.Request, resp *http.Response, err error) {
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("url", redactURL(req.URL.String())),
+	}
+	if resp != nil {
+		attrs = append(attrs,
+			slog.Int("status", resp.StatusCode),
+			slog.Int64("content_length", resp.ContentLength),
+			slog.String("request_id", resp.Header.Get("X-RequestId")))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+		az.Logger().LogAttrs(ctx, slog.LevelWarn, "azure request failed", attrs...)
+		return
+	}
+	az.Logger().LogAttrs(ctx, slog.LevelDebug, "azure response", attrs...)
+}
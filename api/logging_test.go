@@ -0,0 +1,69 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_redactHeaders(t *testing.T) {
+	h := http.Header{}
+	h.Set("Ocp-Apim-Subscription-Key", "secret-key")
+	h.Set("Authorization", "Bearer secret-token")
+	h.Set("Content-Type", "application/ssml+xml")
+
+	got := redactHeaders(h)
+	assert.Equal(t, redacted, got.Get("Ocp-Apim-Subscription-Key"))
+	assert.Equal(t, "Bearer "+redacted, got.Get("Authorization"))
+	assert.Equal(t, "application/ssml+xml", got.Get("Content-Type"))
+	assert.Equal(t, "Bearer secret-token", h.Get("Authorization"), "the original headers are left alone")
+}
+
+func TestLoggingRedaction(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RequestId", "req-1")
+		_, _ = w.Write([]byte("audio"))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		logText  bool
+		wantText bool
+	}{
+		{name: "text redacted by default"},
+		{name: "text logged when enabled", logText: true, wantText: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			az := &AzureTTSClient{
+				HTTPClient:      srv.Client(),
+				TextToSpeechURL: srv.URL,
+				SubscriptionKey: "secret-key",
+				AccessToken:     "secret-token",
+			}
+			assert.NoError(t, WithLogger(slog.New(slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug})))(az))
+			assert.NoError(t, WithSpeechTextLogging(tt.logText)(az))
+
+			_, err := az.TextToSpeech(context.Background(), &model.TextToSpeechRequest{
+				SpeechText: "我的電話是 0912345678",
+				VoiceName:  "zh-TW-HsiaoChenNeural",
+				Locale:     model.LocaleZhTW,
+			})
+			assert.NoError(t, err)
+
+			logged := out.String()
+			assert.NotContains(t, logged, "secret-key")
+			assert.NotContains(t, logged, "secret-token")
+			assert.Contains(t, logged, `"request_id":"req-1"`)
+			assert.Equal(t, tt.wantText, bytes.Contains(out.Bytes(), []byte("0912345678")))
+		})
+	}
+}
@@ -0,0 +1,483 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+)
+
+// Inline markup lets plain SpeechText carry SSML features without hand-written XML:
+//
+//	[[break]] [[break:500ms]] [[break:strong]]
+//	[[silence:200ms]] [[silence:Sentenceboundary-exact:200ms]]
+//	[[emphasis|text]] [[emphasis:strong|text]]
+//	[[say-as:digits|1234]] [[say-as:date:ymd|2024-03-15]]
+//	[[phoneme:ipa:təˈmeɪtoʊ|tomato]]
+//	[[sub:World Wide Web|WWW]]
+//	[[style:cheerful|text]] [[style:cheerful:2|text]] [[style:calm:1:OlderAdultMale|text]]
+//	[[audio:https://example.com/beep.wav|fallback text]]
+//
+// A backslash escapes the next character, so "\[[" is a literal "[[" and "\|" a literal "|" inside a tag.
+// Style switches can only appear at the top level since the service does not allow nested express-as elements.
+
+var (
+	reMarkupDuration = regexp.MustCompile(`^\d+(\.\d+)?(ms|s)$`)
+
+	breakStrengths  = []string{"x-weak", "weak", "medium", "strong", "x-strong"}
+	emphasisLevels  = []string{"reduced", "none", "moderate", "strong"}
+	phonemeAlphabet = []string{"ipa", "sapi", "ups", "x-sampa"}
+	silenceTypes    = []string{
+		"Leading", "Leading-exact", "Tailing", "Tailing-exact", "Sentenceboundary", "Sentenceboundary-exact",
+		"Comma-exact", "Semicolon-exact", "Enumerationcomma-exact",
+	}
+	sayAsTypes = []string{
+		"address", "cardinal", "characters", "currency", "date", "digits", "duration", "fraction",
+		"name", "number", "number_digit", "ordinal", "spell-out", "telephone", "time",
+	}
+)
+
+var xmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;")
+
+// EscapeText escapes text for use as the SpeechText of a request, so that characters such as & and <
+// are spoken instead of read as SSML.
+func EscapeText(text string) string {
+	return xmlEscaper.Replace(text)
+}
+
+// markupNode is either plain text (tag == "") or a [[tag:args|body]] element.
+type markupNode struct {
+	tag       string
+	args      []string
+	text      string
+	body      []markupNode
+	hasBody   bool
+	line, col int
+}
+
+// markupXML renders the XML payload for speechText containing inline markup.
+// With languages, the language runs of the top-level text are spoken in their own language.
+func markupXML(speechText, description string, locale model.Locale, gender model.Gender,
+	prosody *model.Prosody, style *model.TTSStyle, languages *model.LanguageDetection,
+) (string, error) {
+	nodes, err := parseMarkup(speechText)
+	if err != nil {
+		return "", err
+	}
+	segments, err := markupRenderer{locale: locale, voice: description, languages: languages}.renderMarkup(nodes)
+	if err != nil {
+		return "", err
+	}
+	return segmentsXML(segments, description, locale, gender, prosody, style), nil
+}
+
+type markupParser struct {
+	src       []rune
+	pos       int
+	line, col int
+}
+
+// parseMarkup parses text into markup nodes. Syntax errors are reported as *model.MarkupError.
+func parseMarkup(text string) ([]markupNode, error) {
+	p := &markupParser{src: []rune(text), line: 1, col: 1}
+	nodes, _, err := p.parseNodes(false)
+	return nodes, err
+}
+
+func (p *markupParser) errorf(line, col int, format string, args ...any) error {
+	return &model.MarkupError{Line: line, Column: col, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *markupParser) peek(s string) bool {
+	for i, r := range []rune(s) {
+		if p.pos+i >= len(p.src) || p.src[p.pos+i] != r {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *markupParser) next() rune {
+	r := p.src[p.pos]
+	p.pos++
+	if r == '\n' {
+		p.line++
+		p.col = 1
+	} else {
+		p.col++
+	}
+	return r
+}
+
+// escaped consumes a backslash escape and returns the escaped character.
+func (p *markupParser) escaped() (rune, error) {
+	line, col := p.line, p.col
+	p.next()
+	if p.pos >= len(p.src) {
+		return 0, p.errorf(line, col, "dangling escape character")
+	}
+	return p.next(), nil
+}
+
+// parseNodes parses text and tags until the end of input or, when nested, until the "]]" closing
+// the enclosing tag. closed reports whether that "]]" was found; it is left unconsumed.
+func (p *markupParser) parseNodes(nested bool) (nodes []markupNode, closed bool, err error) {
+	var text strings.Builder
+	textLine, textCol := p.line, p.col
+	flush := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, markupNode{text: text.String(), line: textLine, col: textCol})
+			text.Reset()
+		}
+		textLine, textCol = p.line, p.col
+	}
+
+	for p.pos < len(p.src) {
+		switch {
+		case p.src[p.pos] == '\\':
+			r, err := p.escaped()
+			if err != nil {
+				return nil, false, err
+			}
+			text.WriteRune(r)
+		case p.peek("[["):
+			flush()
+			node, err := p.parseTag()
+			if err != nil {
+				return nil, false, err
+			}
+			nodes = append(nodes, node)
+			textLine, textCol = p.line, p.col
+		case nested && p.peek("]]"):
+			flush()
+			return nodes, true, nil
+		default:
+			text.WriteRune(p.next())
+		}
+	}
+	flush()
+	return nodes, false, nil
+}
+
+// parseTag parses a [[name:arg:arg|body]] element starting at the current position.
+func (p *markupParser) parseTag() (markupNode, error) {
+	node := markupNode{line: p.line, col: p.col}
+	p.next()
+	p.next()
+
+	var args []string
+	var current strings.Builder
+loop:
+	for {
+		switch {
+		case p.pos >= len(p.src):
+			return node, p.errorf(node.line, node.col, "unterminated tag, expected \"]]\"")
+		case p.src[p.pos] == '\\':
+			r, err := p.escaped()
+			if err != nil {
+				return node, err
+			}
+			current.WriteRune(r)
+		case p.peek("[["):
+			return node, p.errorf(p.line, p.col, "unexpected \"[[\" in tag arguments")
+		case p.peek("]]"):
+			p.next()
+			p.next()
+			args = append(args, current.String())
+			break loop
+		case p.src[p.pos] == ':':
+			p.next()
+			args = append(args, current.String())
+			current.Reset()
+		case p.src[p.pos] == '|':
+			p.next()
+			args = append(args, current.String())
+			body, closed, err := p.parseNodes(true)
+			if err != nil {
+				return node, err
+			}
+			if !closed {
+				return node, p.errorf(node.line, node.col, "unterminated tag, expected \"]]\"")
+			}
+			p.next()
+			p.next()
+			node.body, node.hasBody = body, true
+			break loop
+		default:
+			current.WriteRune(p.next())
+		}
+	}
+
+	node.tag = strings.ToLower(strings.TrimSpace(args[0]))
+	node.args = args[1:]
+	if node.tag == "" {
+		return node, p.errorf(node.line, node.col, "missing tag name")
+	}
+	return node, nil
+}
+
+// markupRenderer translates parsed markup into SSML for one locale.
+type markupRenderer struct {
+	locale    model.Locale
+	voice     string
+	languages *model.LanguageDetection
+}
+
+// renderMarkup turns parsed nodes into SSML segments, starting a new segment at every style switch.
+func (r markupRenderer) renderMarkup(nodes []markupNode) ([]ssmlSegment, error) {
+	var segments []ssmlSegment
+	var current strings.Builder
+	for _, node := range nodes {
+		if node.tag == "" && r.languages != nil {
+			if current.Len() > 0 {
+				segments = append(segments, ssmlSegment{body: current.String()})
+				current.Reset()
+			}
+			segments = append(segments, languageSegments(node.text, r.voice, r.locale, r.languages, renderMarkupText)...)
+			continue
+		}
+		if node.tag != "style" {
+			text, err := r.renderMarkupNode(node)
+			if err != nil {
+				return nil, err
+			}
+			current.WriteString(text)
+			continue
+		}
+
+		style, err := markupStyle(node)
+		if err != nil {
+			return nil, err
+		}
+		body, err := r.renderMarkupNodes(node.body)
+		if err != nil {
+			return nil, err
+		}
+		if current.Len() > 0 {
+			segments = append(segments, ssmlSegment{body: current.String()})
+			current.Reset()
+		}
+		segments = append(segments, ssmlSegment{style: style, body: body})
+	}
+	if current.Len() > 0 || len(segments) == 0 {
+		segments = append(segments, ssmlSegment{body: current.String()})
+	}
+	return segments, nil
+}
+
+func (r markupRenderer) renderMarkupNodes(nodes []markupNode) (string, error) {
+	var b strings.Builder
+	for _, node := range nodes {
+		if node.tag == "style" {
+			return "", markupErrorf(node, "style switches can't be nested in other tags")
+		}
+		text, err := r.renderMarkupNode(node)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(text)
+	}
+	return b.String(), nil
+}
+
+func (r markupRenderer) renderMarkupNode(node markupNode) (string, error) {
+	switch node.tag {
+	case "":
+		return renderMarkupText(node.text, r.locale), nil
+	case "break":
+		return renderBreak(node)
+	case "silence":
+		return renderSilence(node)
+	case "emphasis":
+		return r.renderEmphasis(node)
+	case "say-as":
+		return renderSayAs(node)
+	case "phoneme":
+		return renderPhoneme(node)
+	case "sub":
+		return renderSub(node)
+	case "audio":
+		return r.renderAudio(node)
+	}
+	return "", markupErrorf(node, "unknown tag %q", node.tag)
+}
+
+// renderMarkupText escapes plain markup text and rewrites it like processSpeechText.
+func renderMarkupText(text string, locale model.Locale) string {
+	return processSpeechText(xmlEscaper.Replace(text), locale)
+}
+
+func renderBreak(node markupNode) (string, error) {
+	if err := node.expect(0, 1, false); err != nil {
+		return "", err
+	}
+	if len(node.args) == 0 {
+		return `<break/>`, nil
+	}
+	if reMarkupDuration.MatchString(node.args[0]) {
+		return fmt.Sprintf(`<break time="%s"/>`, node.args[0]), nil
+	}
+	if err := node.oneOf(node.args[0], breakStrengths); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`<break strength="%s"/>`, node.args[0]), nil
+}
+
+func renderSilence(node markupNode) (string, error) {
+	if err := node.expect(1, 2, false); err != nil {
+		return "", err
+	}
+	silenceType, value := "Sentenceboundary", node.args[len(node.args)-1]
+	if len(node.args) == 2 {
+		silenceType = node.args[0]
+	}
+	if err := node.oneOf(silenceType, silenceTypes); err != nil {
+		return "", err
+	}
+	if !reMarkupDuration.MatchString(value) {
+		return "", markupErrorf(node, "invalid duration %q", value)
+	}
+	return fmt.Sprintf(`<mstts:silence type="%s" value="%s"/>`, silenceType, value), nil
+}
+
+func (r markupRenderer) renderEmphasis(node markupNode) (string, error) {
+	if err := node.expect(0, 1, true); err != nil {
+		return "", err
+	}
+	level := "moderate"
+	if len(node.args) == 1 {
+		level = node.args[0]
+	}
+	if err := node.oneOf(level, emphasisLevels); err != nil {
+		return "", err
+	}
+	body, err := r.renderMarkupNodes(node.body)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`<emphasis level="%s">%s</emphasis>`, level, body), nil
+}
+
+func renderPhoneme(node markupNode) (string, error) {
+	if err := node.expect(2, 2, true); err != nil {
+		return "", err
+	}
+	if err := node.oneOf(node.args[0], phonemeAlphabet); err != nil {
+		return "", err
+	}
+	body, err := node.plainBody()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`<phoneme alphabet="%s" ph="%s">%s</phoneme>`, node.args[0], xmlEscaper.Replace(node.args[1]), body), nil
+}
+
+func renderSub(node markupNode) (string, error) {
+	if err := node.expect(1, 1, true); err != nil {
+		return "", err
+	}
+	body, err := node.plainBody()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`<sub alias="%s">%s</sub>`, xmlEscaper.Replace(node.args[0]), body), nil
+}
+
+func (r markupRenderer) renderAudio(node markupNode) (string, error) {
+	// URLs contain ':' so every argument belongs to the source.
+	src := xmlEscaper.Replace(strings.Join(node.args, ":"))
+	if src == "" {
+		return "", markupErrorf(node, "audio requires a source URL")
+	}
+	if !node.hasBody {
+		return fmt.Sprintf(`<audio src="%s"/>`, src), nil
+	}
+	body, err := r.renderMarkupNodes(node.body)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`<audio src="%s">%s</audio>`, src, body), nil
+}
+
+func renderSayAs(node markupNode) (string, error) {
+	if err := node.expect(1, 3, true); err != nil {
+		return "", err
+	}
+	if err := node.oneOf(node.args[0], sayAsTypes); err != nil {
+		return "", err
+	}
+	body, err := node.plainBody()
+	if err != nil {
+		return "", err
+	}
+
+	attrs := fmt.Sprintf(`interpret-as="%s"`, node.args[0])
+	if len(node.args) > 1 && node.args[1] != "" {
+		attrs += fmt.Sprintf(` format="%s"`, xmlEscaper.Replace(node.args[1]))
+	}
+	if len(node.args) > 2 && node.args[2] != "" {
+		attrs += fmt.Sprintf(` detail="%s"`, xmlEscaper.Replace(node.args[2]))
+	}
+	return fmt.Sprintf(`<say-as %s>%s</say-as>`, attrs, body), nil
+}
+
+// markupStyle builds the style of a [[style:name:degree:role|text]] switch.
+func markupStyle(node markupNode) (*model.TTSStyle, error) {
+	if err := node.expect(1, 3, true); err != nil {
+		return nil, err
+	}
+	style := &model.TTSStyle{Style: node.args[0]}
+	if len(node.args) > 1 {
+		style.StyleDegree = node.args[1]
+	}
+	if len(node.args) > 2 {
+		style.Role = node.args[2]
+	}
+	if style.Style == "" && style.Role == "" {
+		return nil, markupErrorf(node, "style requires a style name or role")
+	}
+	return style, nil
+}
+
+func markupErrorf(node markupNode, format string, args ...any) error {
+	return &model.MarkupError{Line: node.line, Column: node.col, Message: fmt.Sprintf(format, args...)}
+}
+
+// expect checks the number of arguments and whether the tag needs a "|text" body.
+func (n markupNode) expect(minArgs, maxArgs int, body bool) error {
+	if len(n.args) < minArgs || len(n.args) > maxArgs {
+		if minArgs == maxArgs {
+			return markupErrorf(n, "%s takes %d argument(s), got %d", n.tag, minArgs, len(n.args))
+		}
+		return markupErrorf(n, "%s takes %d to %d arguments, got %d", n.tag, minArgs, maxArgs, len(n.args))
+	}
+	if body && !n.hasBody {
+		return markupErrorf(n, "%s requires text, e.g. [[%s|text]]", n.tag, n.tag)
+	}
+	if !body && n.hasBody {
+		return markupErrorf(n, "%s does not take text", n.tag)
+	}
+	return nil
+}
+
+func (n markupNode) oneOf(value string, allowed []string) error {
+	for _, a := range allowed {
+		if value == a {
+			return nil
+		}
+	}
+	return markupErrorf(n, "invalid %s value %q, expected one of %s", n.tag, value, strings.Join(allowed, ", "))
+}
+
+// plainBody returns the escaped body of tags whose content must be plain text.
+func (n markupNode) plainBody() (string, error) {
+	var b strings.Builder
+	for _, child := range n.body {
+		if child.tag != "" {
+			return "", markupErrorf(child, "%s can't contain other tags", n.tag)
+		}
+		b.WriteString(xmlEscaper.Replace(child.text))
+	}
+	return b.String(), nil
+}
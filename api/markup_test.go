@@ -0,0 +1,85 @@
+package api
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_markupXML(t *testing.T) {
+	tests := []struct {
+		name       string
+		speechText string
+		style      *model.TTSStyle
+		want       string
+	}{
+		{
+			name:       "break and say-as",
+			speechText: "Please wait[[break:500ms]] then press [[say-as:digits|1234]]",
+			want:       `<speak version='1.0' xml:lang='en-US'><voice xml:lang='en-US' xml:gender='Female' name='test'>Please wait<break time="500ms"/> then press <say-as interpret-as="digits">1234</say-as></voice></speak>`,
+		},
+		{
+			name:       "escaping",
+			speechText: `Tom & Jerry \[[not a tag]] <b>`,
+			want:       `<speak version='1.0' xml:lang='en-US'><voice xml:lang='en-US' xml:gender='Female' name='test'>Tom &amp; Jerry [[not a tag]] &lt;b&gt;</voice></speak>`,
+		},
+		{
+			name:       "nested emphasis, phoneme, sub and audio",
+			speechText: "[[emphasis:strong|very [[phoneme:ipa:təˈmeɪtoʊ|tomato]]]] [[sub:World Wide Web|WWW]][[audio:https://example.com/a.wav|beep]][[silence:Leading:100ms]]",
+			want: `<speak version='1.0' xml:lang='en-US'><voice xml:lang='en-US' xml:gender='Female' name='test'>` +
+				`<emphasis level="strong">very <phoneme alphabet="ipa" ph="təˈmeɪtoʊ">tomato</phoneme></emphasis> <sub alias="World Wide Web">WWW</sub>` +
+				`<audio src="https://example.com/a.wav">beep</audio><mstts:silence type="Leading" value="100ms"/></voice></speak>`,
+		},
+		{
+			name:       "style switch keeps request style around it",
+			speechText: "Hello [[style:excited:2|great news]] bye",
+			style:      &model.TTSStyle{Style: "calm"},
+			want: `<speak version="1.0" xmlns="http://www.w3.org/2001/10/synthesis" xmlns:mstts="https://www.w3.org/2001/mstts" xml:lang="en-US"><voice name="test">` +
+				`<mstts:express-as style="calm">Hello </mstts:express-as><mstts:express-as style="excited" styledegree="2">great news</mstts:express-as><mstts:express-as style="calm"> bye</mstts:express-as>` +
+				`</voice></speak>`,
+		},
+		{
+			name:       "style arguments are escaped",
+			speechText: `[[style:sad" role="x|hi]]`,
+			want: `<speak version="1.0" xmlns="http://www.w3.org/2001/10/synthesis" xmlns:mstts="https://www.w3.org/2001/mstts" xml:lang="en-US"><voice name="test">` +
+				`<mstts:express-as style="sad&quot; role=&quot;x">hi</mstts:express-as></voice></speak>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := markupXML(tt.speechText, "test", model.LocaleEnUS, model.GenderFemale, nil, tt.style, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_markupXMLErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		speechText string
+		line, col  int
+	}{
+		{name: "unterminated", speechText: "hello [[break:500ms", line: 1, col: 7},
+		{name: "unknown tag", speechText: "line one\n  [[shout|hi]]", line: 2, col: 3},
+		{name: "bad break", speechText: "[[break:loud]]", line: 1, col: 1},
+		{name: "missing body", speechText: "[[say-as:digits]]", line: 1, col: 1},
+		{name: "nested style", speechText: "x[[emphasis|[[style:sad|no]]]]", line: 1, col: 13},
+		{name: "tag inside say-as", speechText: "[[say-as:digits|1[[break]]2]]", line: 1, col: 18},
+		{name: "dangling escape", speechText: `abc\`, line: 1, col: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := markupXML(tt.speechText, "test", model.LocaleEnUS, model.GenderFemale, nil, nil, nil)
+			var markupErr *model.MarkupError
+			if assert.True(t, errors.As(err, &markupErr), "got %v", err) {
+				assert.Equal(t, tt.line, markupErr.Line)
+				assert.Equal(t, tt.col, markupErr.Column)
+			}
+		})
+	}
+}
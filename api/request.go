@@ -4,21 +4,27 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"log/slog"
 	"net/http"
 	"os"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/barkingdog-ai/azure-tts/model"
 )
 
 // TTSApiXMLPayload templates the payload required for API.
 // See: https://docs.microsoft.com/en-us/azure/cognitive-services/speech-service/rest-text-to-speech#sample-request
-const ttsAPIXMLPayload = `<speak version='1.0' xml:lang='%s'><voice xml:lang='%s' xml:gender='%s' name='%s'><prosody rate='%s' pitch='%s'>%s</prosody></voice></speak>`
+const ttsAPIXMLPayload = `<speak version='1.0' xml:lang='%s'><voice xml:lang='%s' xml:gender='%s' name='%s'>%s</voice></speak>`
+
+// ssmlSpeakOpen opens the payload used when speaking styles, roles, languages or several voices are requested.
+const ssmlSpeakOpen = `<speak version="1.0" xmlns="http://www.w3.org/2001/10/synthesis" xmlns:mstts="https://www.w3.org/2001/mstts" xml:lang="%s">`
 
 func (az *AzureTTSClient) newTokenRequest(ctx context.Context, method, path string, payload any) (*http.Request, error) {
 	bodyReader, err := jsonBodyReader(payload)
@@ -42,9 +48,13 @@ func (az *AzureTTSClient) newTTSRequest(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+	token, err := az.bearerToken(ctx)
+	if err != nil {
+		return nil, err
+	}
 	req.Header.Set("X-Microsoft-OutputFormat", audioOutput.String())
 	req.Header.Set("Content-Type", "application/ssml+xml")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", az.AccessToken))
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 	req.Header.Set("User-Agent", "azuretts")
 
 	return req, nil
@@ -77,7 +87,9 @@ func (az *AzureTTSClient) newSTTRequest(ctx context.Context, method, path string
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Ocp-Apim-Subscription-Key", az.SubscriptionKey)
+	if err := az.authorize(ctx, req); err != nil {
+		return nil, err
+	}
 	req.Header.Set("Content-Type", "audio/wav; codecs=audio/pcm; samplerate=16000")
 	return req, nil
 }
@@ -91,42 +103,74 @@ func (az *AzureTTSClient) newRequest(ctx context.Context, method, path string, p
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", az.AccessToken))
-	req.Header.Set("Ocp-Apim-Subscription-Key", az.SubscriptionKey)
+	if err := az.authorize(ctx, req); err != nil {
+		return nil, err
+	}
 	return req, nil
 }
 
-func (az *AzureTTSClient) performReq(request *http.Request) (*http.Response, error) {
-	client := &http.Client{}
-	ctx := context.Background()
-	req, err := http.NewRequestWithContext(ctx,
-		"POST", "https://eastasia.api.cognitive.microsoft.com/sts/v1.0/issueToken",
-		bytes.NewBuffer([]byte("")))
-	if err != nil {
-		return nil, err
+// authorize authenticates req with the subscription key when the client has one, and otherwise with
+// a bearer token, e.g. an Azure AD token from a TokenSource.
+func (az *AzureTTSClient) authorize(ctx context.Context, req *http.Request) error {
+	if az.SubscriptionKey != "" && az.tokenSource == nil {
+		req.Header.Set("Ocp-Apim-Subscription-Key", az.SubscriptionKey)
+		return nil
 	}
-	// Add required headers
-	req.Header.Add("Ocp-Apim-Subscription-Key", az.SubscriptionKey)
-	req.Header.Add("Content-Length", "0")
-	request = req
-	resp, err := client.Do(req)
+	token, err := az.bearerToken(ctx)
 	if err != nil {
-		return nil, err
-	}
-	if err := checkForSuccess(resp); err != nil {
-		return nil, err
+		return err
 	}
-	return resp, nil
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	return nil
 }
 
+// performRequest sends req once the rate limit allows it, retrying failures as set up by WithRetry.
+// Requests with a body that can't be rewound are sent once.
 func (az *AzureTTSClient) performRequest(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		if err := az.limiter.wait(req.Context()); err != nil {
+			return nil, &model.NetworkError{Region: az.region(), Err: err}
+		}
+		resp, err := az.sendRequest(req)
+		delay, retry := az.retry.delay(attempt, err)
+		if !retry || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+			return resp, err
+		}
+		az.Logger().WarnContext(req.Context(), "retrying request",
+			slog.Int("attempt", attempt), slog.Duration("delay", delay), slog.Any("error", err))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, err
+		}
+		if req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
+
+// sendRequest sends req once and turns unsuccessful responses into errors.
+func (az *AzureTTSClient) sendRequest(req *http.Request) (*http.Response, error) {
+	az.logRequest(req.Context(), req)
 	resp, err := az.HTTPClient.Do(req)
 	if err != nil {
+		err = &model.NetworkError{Region: az.region(), Err: err}
+		az.logResponse(req.Context(), req, nil, err)
 		return nil, err
 	}
-	if err := checkForSuccess(resp); err != nil {
+	recordStatus(req.Context(), resp.StatusCode)
+	if err := az.checkForSuccess(resp); err != nil {
+		az.logResponse(req.Context(), req, resp, err)
 		return nil, err
 	}
+	az.logResponse(req.Context(), req, resp, nil)
 	return resp, nil
 }
 
@@ -157,7 +201,7 @@ func errorMessage(resp *http.Response) string {
 }
 
 // returns an error if this resp includes an error.
-func checkForSuccess(resp *http.Response) error {
+func (az *AzureTTSClient) checkForSuccess(resp *http.Response) error {
 	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
 		return nil
 	}
@@ -170,18 +214,43 @@ func checkForSuccess(resp *http.Response) error {
 	if err := json.Unmarshal(data, &result); err != nil {
 		message := errorMessage(resp)
 		// if we can't decode the json error then create an unexpected error
-		APIError := model.APIError{
-			StatusCode: resp.StatusCode,
-			Type:       "Unexpected",
-			Message:    message + string(data),
+		result.Error = model.APIError{
+			Type:    "Unexpected",
+			Message: message + string(data),
 		}
-
-		return APIError
 	}
 	result.Error.StatusCode = resp.StatusCode
+	result.Error.RequestID = resp.Header.Get("X-RequestId")
+	result.Error.Region = az.region()
+	result.Error.RetryAfter = retryAfter(resp.Header.Get("Retry-After"))
 	return result.Error
 }
 
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func retryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// invalidSSML marks a 400 response of the synthesis endpoint as ErrInvalidSSML.
+func invalidSSML(err error) error {
+	var apiErr model.APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest {
+		return fmt.Errorf("%w: %w", model.ErrInvalidSSML, err)
+	}
+	return err
+}
+
 func jsonBodyReader(body any) (io.Reader, error) {
 	if body == nil {
 		return bytes.NewBuffer(nil), nil
@@ -203,7 +272,119 @@ func getResponseObject(rsp *http.Response, v any) error {
 
 // voiceXML renders the XML payload for the TTS api.
 // For API reference see https://docs.microsoft.com/en-us/azure/cognitive-services/speech-service/rest-text-to-speech#sample-request
-func voiceXML(speechText, description string, locale model.Locale, gender model.Gender, rate, pitch string, style *model.TTSStyle) string {
+func voiceXML(speechText, description string, locale model.Locale, gender model.Gender,
+	prosody *model.Prosody, style *model.TTSStyle, languages *model.LanguageDetection,
+) string {
+	segments := []ssmlSegment{{body: processSpeechText(speechText, locale)}}
+	if languages != nil {
+		segments = languageSegments(speechText, description, locale, languages, processSpeechText)
+	}
+	return segmentsXML(segments, description, locale, gender, prosody, style)
+}
+
+// ssmlSegment is a run of SSML body text spoken in one style. A nil style uses the request style.
+// A segment with a voice is spoken by that voice, without the request style, instead of the request
+// voice; a segment with lang is spoken in that language by the request voice.
+type ssmlSegment struct {
+	style *model.TTSStyle
+	body  string
+	voice string
+	lang  model.Locale
+}
+
+// segmentsXML renders the XML payload for segments, wrapping each one in its prosody, language and
+// speaking style. Consecutive segments of the same voice share a <voice> element.
+func segmentsXML(segments []ssmlSegment, description string, locale model.Locale, gender model.Gender,
+	prosody *model.Prosody, style *model.TTSStyle,
+) string {
+	type voiceBody struct {
+		name string
+		body strings.Builder
+	}
+	var voices []*voiceBody
+	nested := false
+	for _, segment := range segments {
+		text := segment.body
+		// 沒有調整語速、音調等設定時，不包含 prosody 標籤
+		if !prosody.IsZero() {
+			text = fmt.Sprintf("<prosody%s>%s</prosody>", prosody.Attributes(), text)
+		}
+		if segment.lang != "" {
+			nested = true
+			text = fmt.Sprintf(`<lang xml:lang="%s">%s</lang>`, segment.lang, text)
+		}
+
+		name, segmentStyle := description, segment.style
+		if segment.voice != "" {
+			nested = true
+			name = segment.voice
+		} else if segmentStyle == nil {
+			segmentStyle = style
+		}
+		if segmentStyle != nil {
+			nested = true
+			text = expressAsOpen(segmentStyle) + text + `</mstts:express-as>`
+		}
+
+		if len(voices) == 0 || voices[len(voices)-1].name != name {
+			voices = append(voices, &voiceBody{name: name})
+		}
+		voices[len(voices)-1].body.WriteString(text)
+	}
+
+	if !nested {
+		var body string
+		if len(voices) > 0 {
+			body = voices[0].body.String()
+		}
+		return fmt.Sprintf(ttsAPIXMLPayload, locale, locale, gender, description, body)
+	}
+	// 使用帶風格的 SSML 模板
+	var b strings.Builder
+	fmt.Fprintf(&b, ssmlSpeakOpen, locale)
+	for _, v := range voices {
+		fmt.Fprintf(&b, `<voice name="%s">%s</voice>`, v.name, v.body.String())
+	}
+	b.WriteString(`</speak>`)
+	return b.String()
+}
+
+var reVoiceOpen = regexp.MustCompile(`<voice\b[^>]*>`)
+
+// withLexicons references the lexicons of WithLexicons at the start of every voice of ssml.
+func (az *AzureTTSClient) withLexicons(ssml string) string {
+	if len(az.lexicons) == 0 {
+		return ssml
+	}
+	var lexicons strings.Builder
+	for _, uri := range az.lexicons {
+		fmt.Fprintf(&lexicons, `<lexicon uri="%s"/>`, xmlEscaper.Replace(uri))
+	}
+	return reVoiceOpen.ReplaceAllStringFunc(ssml, func(open string) string {
+		return open + lexicons.String()
+	})
+}
+
+// expressAsOpen renders the opening <mstts:express-as> tag for the style and role in style.
+func expressAsOpen(style *model.TTSStyle) string {
+	var b strings.Builder
+	b.WriteString(`<mstts:express-as`)
+	if style.Role != "" {
+		fmt.Fprintf(&b, ` role="%s"`, xmlEscaper.Replace(style.Role))
+	}
+	if style.Style != "" {
+		fmt.Fprintf(&b, ` style="%s"`, xmlEscaper.Replace(style.Style))
+		if style.StyleDegree != "" {
+			fmt.Fprintf(&b, ` styledegree="%s"`, xmlEscaper.Replace(style.StyleDegree))
+		}
+	}
+	b.WriteString(`>`)
+	return b.String()
+}
+
+// processSpeechText rewrites IP addresses, URLs, dates, prices and other number sequences in speechText so
+// that they are read out naturally by the TTS service in the given locale.
+func processSpeechText(speechText string, locale model.Locale) string {
 	processedText := speechText
 
 	// 先处理IP地址
@@ -243,51 +424,14 @@ func voiceXML(speechText, description string, locale model.Locale, gender model.
 		return url
 	})
 
-	// 处理日期和其他数字序列
-	reDate := regexp.MustCompile(`\b(\d{4}|\d{2})\.\d{1,2}\.\d{1,2}\b`)
-	reNumbers := regexp.MustCompile(`\b\d+\.\d+(\.\d+)*\b`)
-	processedText = reNumbers.ReplaceAllStringFunc(processedText, func(match string) string {
-		// 如果已经包含在say-as标签中，跳过处理
-		if strings.Contains(match, "<say-as") {
-			return match
-		}
-
-		// 检查是否是IP地址格式，如果是则跳过（因为已经处理过）
-		if reIP.MatchString(match) {
-			return match
-		}
+	// 依語系加上日期、時間、電話、金額等 say-as 標籤
+	processedText = detectSayAs(processedText, locale)
 
-		// 检查是否是有效日期格式
-		if reDate.MatchString(match) {
-			parts := strings.Split(match, ".")
-			if len(parts) == 3 {
-				month, _ := strconv.Atoi(parts[1])
-				day, _ := strconv.Atoi(parts[2])
-				if month >= 1 && month <= 12 && day >= 1 && day <= 31 {
-					return match
-				}
-			}
-		}
-		return fmt.Sprintf("<say-as interpret-as=\"characters\">%s</say-as>", match)
+	// 其他数字序列（如版本号）逐字朗读，已经在标签中的部分会跳过
+	reNumbers := regexp.MustCompile(`\b\d+\.\d+(\.\d+)*\b`)
+	processedText = replaceOutsideTags(processedText, reNumbers, func(match []string) string {
+		return fmt.Sprintf("<say-as interpret-as=\"characters\">%s</say-as>", match[0])
 	})
 
-	if style != nil {
-		// 使用帶風格的 SSML 模板
-		// 如果 rate 和 pitch 為 0%，則不包含 prosody 標籤
-		if rate == "0%" && pitch == "0%" {
-			styledPayload := `<speak version="1.0" xmlns="http://www.w3.org/2001/10/synthesis" xmlns:mstts="https://www.w3.org/2001/mstts" xml:lang="%s"><voice name="%s"><mstts:express-as style="%s" styledegree="%s">%s</mstts:express-as></voice></speak>`
-			return fmt.Sprintf(styledPayload, locale, description, style.Style, style.StyleDegree, processedText)
-		} else {
-			styledPayload := `<speak version="1.0" xmlns="http://www.w3.org/2001/10/synthesis" xmlns:mstts="https://www.w3.org/2001/mstts" xml:lang="%s"><voice name="%s"><mstts:express-as style="%s" styledegree="%s"><prosody rate="%s" pitch="%s">%s</prosody></mstts:express-as></voice></speak>`
-			return fmt.Sprintf(styledPayload, locale, description, style.Style, style.StyleDegree, rate, pitch, processedText)
-		}
-	}
-
-	// 如果 rate 和 pitch 為 0%，則不包含 prosody 標籤
-	if rate == "0%" && pitch == "0%" {
-		basicPayload := `<speak version='1.0' xml:lang='%s'><voice xml:lang='%s' xml:gender='%s' name='%s'>%s</voice></speak>`
-		return fmt.Sprintf(basicPayload, locale, locale, gender, description, processedText)
-	}
-
-	return fmt.Sprintf(ttsAPIXMLPayload, locale, locale, gender, description, rate, pitch, processedText)
+	return processedText
 }
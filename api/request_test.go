@@ -14,8 +14,8 @@ func Test_voiceXML(t *testing.T) {
 		description string
 		locale      model.Locale
 		gender      model.Gender
-		rate        string
-		pitch       string
+		prosody     *model.Prosody
+		style       *model.TTSStyle
 		want        string
 	}{
 		{
@@ -24,9 +24,7 @@ func Test_voiceXML(t *testing.T) {
 			description: "test",
 			locale:      model.LocaleZhCN,
 			gender:      model.GenderFemale,
-			rate:        "0%",
-			pitch:       "0%",
-			want:        `<speak version='1.0' xml:lang='zh-CN'><voice xml:lang='zh-CN' xml:gender='Female' name='test'><prosody rate='0%' pitch='0%'>服务器IP是<say-as interpret-as="characters">192.168.1.1</say-as>和<say-as interpret-as="characters">10.0.0.1</say-as></prosody></voice></speak>`,
+			want:        `<speak version='1.0' xml:lang='zh-CN'><voice xml:lang='zh-CN' xml:gender='Female' name='test'>服务器IP是<say-as interpret-as="characters">192.168.1.1</say-as>和<say-as interpret-as="characters">10.0.0.1</say-as></voice></speak>`,
 		},
 		{
 			name:        "普通URL测试",
@@ -34,9 +32,7 @@ func Test_voiceXML(t *testing.T) {
 			description: "test",
 			locale:      model.LocaleZhCN,
 			gender:      model.GenderFemale,
-			rate:        "0%",
-			pitch:       "0%",
-			want:        `<speak version='1.0' xml:lang='zh-CN'><voice xml:lang='zh-CN' xml:gender='Female' name='test'><prosody rate='0%' pitch='0%'>请访问api.ai-amaze.com查看详情</prosody></voice></speak>`,
+			want:        `<speak version='1.0' xml:lang='zh-CN'><voice xml:lang='zh-CN' xml:gender='Female' name='test'>请访问api.ai-amaze.com查看详情</voice></speak>`,
 		},
 		{
 			name:        "IP形式URL测试",
@@ -44,9 +40,7 @@ func Test_voiceXML(t *testing.T) {
 			description: "test",
 			locale:      model.LocaleZhCN,
 			gender:      model.GenderFemale,
-			rate:        "0%",
-			pitch:       "0%",
-			want:        `<speak version='1.0' xml:lang='zh-CN'><voice xml:lang='zh-CN' xml:gender='Female' name='test'><prosody rate='0%' pitch='0%'>请访问<say-as interpret-as="characters">192.168.1.1</say-as>查看详情</prosody></voice></speak>`,
+			want:        `<speak version='1.0' xml:lang='zh-CN'><voice xml:lang='zh-CN' xml:gender='Female' name='test'>请访问<say-as interpret-as="characters">192.168.1.1</say-as>查看详情</voice></speak>`,
 		},
 		{
 			name:        "Markdown URL测试",
@@ -54,9 +48,7 @@ func Test_voiceXML(t *testing.T) {
 			description: "test",
 			locale:      model.LocaleZhCN,
 			gender:      model.GenderFemale,
-			rate:        "0%",
-			pitch:       "0%",
-			want:        `<speak version='1.0' xml:lang='zh-CN'><voice xml:lang='zh-CN' xml:gender='Female' name='test'><prosody rate='0%' pitch='0%'>点击官方网站了解更多</prosody></voice></speak>`,
+			want:        `<speak version='1.0' xml:lang='zh-CN'><voice xml:lang='zh-CN' xml:gender='Female' name='test'>点击官方网站了解更多</voice></speak>`,
 		},
 		{
 			name:        "混合测试",
@@ -64,9 +56,7 @@ func Test_voiceXML(t *testing.T) {
 			description: "test",
 			locale:      model.LocaleZhCN,
 			gender:      model.GenderFemale,
-			rate:        "0%",
-			pitch:       "0%",
-			want:        `<speak version='1.0' xml:lang='zh-CN'><voice xml:lang='zh-CN' xml:gender='Female' name='test'><prosody rate='0%' pitch='0%'>服务器IP是<say-as interpret-as="characters">192.168.1.1</say-as>，请访问api.ai-amaze.com或官方网站了解更多</prosody></voice></speak>`,
+			want:        `<speak version='1.0' xml:lang='zh-CN'><voice xml:lang='zh-CN' xml:gender='Female' name='test'>服务器IP是<say-as interpret-as="characters">192.168.1.1</say-as>，请访问api.ai-amaze.com或官方网站了解更多</voice></speak>`,
 		},
 		{
 			name:        "有效日期格式测试",
@@ -74,9 +64,7 @@ func Test_voiceXML(t *testing.T) {
 			description: "test",
 			locale:      model.LocaleZhCN,
 			gender:      model.GenderFemale,
-			rate:        "0%",
-			pitch:       "0%",
-			want:        `<speak version='1.0' xml:lang='zh-CN'><voice xml:lang='zh-CN' xml:gender='Female' name='test'><prosody rate='0%' pitch='0%'>日期是2024.03.15和24.03.15</prosody></voice></speak>`,
+			want:        `<speak version='1.0' xml:lang='zh-CN'><voice xml:lang='zh-CN' xml:gender='Female' name='test'>日期是<say-as interpret-as="date" format="ymd">2024.03.15</say-as>和<say-as interpret-as="date" format="ymd">24.03.15</say-as></voice></speak>`,
 		},
 		{
 			name:        "无效日期格式测试",
@@ -84,9 +72,7 @@ func Test_voiceXML(t *testing.T) {
 			description: "test",
 			locale:      model.LocaleZhCN,
 			gender:      model.GenderFemale,
-			rate:        "0%",
-			pitch:       "0%",
-			want:        `<speak version='1.0' xml:lang='zh-CN'><voice xml:lang='zh-CN' xml:gender='Female' name='test'><prosody rate='0%' pitch='0%'>版本号是<say-as interpret-as="characters">8.2.3</say-as>和<say-as interpret-as="characters">2024.13.32</say-as></prosody></voice></speak>`,
+			want:        `<speak version='1.0' xml:lang='zh-CN'><voice xml:lang='zh-CN' xml:gender='Female' name='test'>版本号是<say-as interpret-as="characters">8.2.3</say-as>和<say-as interpret-as="characters">2024.13.32</say-as></voice></speak>`,
 		},
 		{
 			name:        "混合日期和版本号测试",
@@ -94,15 +80,32 @@ func Test_voiceXML(t *testing.T) {
 			description: "test",
 			locale:      model.LocaleZhCN,
 			gender:      model.GenderFemale,
-			rate:        "0%",
-			pitch:       "0%",
-			want:        `<speak version='1.0' xml:lang='zh-CN'><voice xml:lang='zh-CN' xml:gender='Female' name='test'><prosody rate='0%' pitch='0%'>更新时间是2024.03.15，当前版本<say-as interpret-as="characters">8.2.3</say-as></prosody></voice></speak>`,
+			want:        `<speak version='1.0' xml:lang='zh-CN'><voice xml:lang='zh-CN' xml:gender='Female' name='test'>更新时间是<say-as interpret-as="date" format="ymd">2024.03.15</say-as>，当前版本<say-as interpret-as="characters">8.2.3</say-as></voice></speak>`,
+		},
+		{
+			name:        "語速音調测试",
+			speechText:  "你好",
+			description: "test",
+			locale:      model.LocaleZhTW,
+			gender:      model.GenderFemale,
+			prosody:     &model.Prosody{Rate: model.Percent(15), Pitch: model.Semitones(-2), Volume: model.Level("loud")},
+			want:        `<speak version='1.0' xml:lang='zh-TW'><voice xml:lang='zh-TW' xml:gender='Female' name='test'><prosody rate="+15%" pitch="-2st" volume="loud">你好</prosody></voice></speak>`,
+		},
+		{
+			name:        "風格與語調测试",
+			speechText:  "你好",
+			description: "zh-TW-HsiaoChenNeural",
+			locale:      model.LocaleZhTW,
+			gender:      model.GenderFemale,
+			prosody:     &model.Prosody{Contour: []model.ContourPoint{{Position: 0, Pitch: model.RelativeHertz(20)}, {Position: 80, Pitch: model.Percent(-10)}}},
+			style:       &model.TTSStyle{Style: "cheerful", StyleDegree: "2"},
+			want:        `<speak version="1.0" xmlns="http://www.w3.org/2001/10/synthesis" xmlns:mstts="https://www.w3.org/2001/mstts" xml:lang="zh-TW"><voice name="zh-TW-HsiaoChenNeural"><mstts:express-as style="cheerful" styledegree="2"><prosody contour="(0%,+20Hz) (80%,-10%)">你好</prosody></mstts:express-as></voice></speak>`,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := voiceXML(tt.speechText, tt.description, tt.locale, tt.gender, tt.rate, tt.pitch)
+			got := voiceXML(tt.speechText, tt.description, tt.locale, tt.gender, tt.prosody, tt.style, nil)
 			assert.Equal(t, tt.want, got)
 		})
 	}
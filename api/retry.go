@@ -0,0 +1,46 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+)
+
+// retryPolicy decides whether and when a failed request is sent again, see WithRetry.
+type retryPolicy struct {
+	maxAttempts int
+	backoff     time.Duration
+}
+
+// delay returns how long to wait before sending a request again that failed with err on its attempt-th
+// try, and false when it must not be retried.
+func (p *retryPolicy) delay(attempt int, err error) (time.Duration, bool) {
+	if p == nil || err == nil || attempt >= p.maxAttempts || !retryable(err) {
+		return 0, false
+	}
+	if d, ok := model.RetryAfter(err); ok {
+		return d, true
+	}
+	return p.backoff << (attempt - 1), true
+}
+
+// retryable reports whether err is a throttled, unavailable or unreachable service rather than a
+// rejected request or a canceled context.
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr model.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	return errors.Is(err, model.ErrNetwork)
+}
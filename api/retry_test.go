@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantAttempts int32
+		wantErr      error
+	}{
+		{name: "unavailable then ok", statuses: []int{503, 502, 200}, wantAttempts: 3},
+		{name: "throttled until out of attempts", statuses: []int{429, 429, 429, 429}, wantAttempts: 3, wantErr: model.ErrThrottled},
+		{name: "rejected request", statuses: []int{400, 200}, wantAttempts: 1, wantErr: model.ErrInvalidSSML},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := attempts.Add(1)
+				body, _ := io.ReadAll(r.Body)
+				assert.Contains(t, string(body), "你好", "the body is sent again")
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(tt.statuses[n-1])
+				_, _ = w.Write([]byte("audio"))
+			}))
+			defer srv.Close()
+
+			az := &AzureTTSClient{HTTPClient: srv.Client(), TextToSpeechURL: srv.URL}
+			require.NoError(t, WithRetry(3, time.Millisecond)(az))
+			audio, err := az.TextToSpeech(context.Background(), &model.TextToSpeechRequest{SpeechText: "你好", VoiceName: "v"})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "audio", string(audio))
+			}
+			assert.Equal(t, tt.wantAttempts, attempts.Load())
+		})
+	}
+
+	assert.Error(t, WithRetry(0, time.Second)(&AzureTTSClient{}))
+}
+
+func Test_rateLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newRateLimiter(2, 2)
+	l.now = func() time.Time { return now }
+
+	assert.Equal(t, time.Duration(0), l.reserve())
+	assert.Equal(t, time.Duration(0), l.reserve())
+	assert.Equal(t, 500*time.Millisecond, l.reserve())
+	assert.Equal(t, time.Second, l.reserve(), "waiting callers queue up")
+
+	now = now.Add(2 * time.Second)
+	assert.Equal(t, time.Duration(0), l.reserve())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.NoError(t, l.wait(ctx), "a token is left")
+	assert.ErrorIs(t, l.wait(ctx), context.Canceled)
+	assert.NoError(t, (*rateLimiter)(nil).wait(ctx))
+}
+
+func TestLexicons(t *testing.T) {
+	az := &AzureTTSClient{}
+	require.NoError(t, WithLexicons("https://example.com/lexicon.xml?a=1&b=2")(az))
+	assert.Error(t, WithLexicons("lexicon.xml")(az))
+
+	ssml := `<speak><voice name="a">x</voice><voice xml:lang='zh-TW' name='b'>y</voice></speak>`
+	assert.Equal(t, `<speak><voice name="a"><lexicon uri="https://example.com/lexicon.xml?a=1&amp;b=2"/>x</voice>`+
+		`<voice xml:lang='zh-TW' name='b'><lexicon uri="https://example.com/lexicon.xml?a=1&amp;b=2"/>y</voice></speak>`,
+		az.withLexicons(ssml))
+	assert.Equal(t, ssml, (&AzureTTSClient{}).withLexicons(ssml))
+}
@@ -0,0 +1,260 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+)
+
+// SayAs renders a <say-as> element. format may be empty. All of interpretAs, format and text are escaped.
+// See: https://learn.microsoft.com/en-us/azure/ai-services/speech-service/speech-synthesis-markup-pronunciation#say-as-element
+func SayAs(interpretAs, format, text string) string {
+	if format == "" {
+		return fmt.Sprintf(`<say-as interpret-as="%s">%s</say-as>`, xmlEscaper.Replace(interpretAs), xmlEscaper.Replace(text))
+	}
+	return fmt.Sprintf(`<say-as interpret-as="%s" format="%s">%s</say-as>`,
+		xmlEscaper.Replace(interpretAs), xmlEscaper.Replace(format), xmlEscaper.Replace(text))
+}
+
+// SayAsDate reads t as a date written in the given field order.
+func SayAsDate(t time.Time, format model.DateFormat) string {
+	layout := map[model.DateFormat]string{
+		model.DateYMD: "2006-01-02",
+		model.DateMDY: "01/02/2006",
+		model.DateDMY: "02/01/2006",
+		model.DateYM:  "2006-01",
+		model.DateMD:  "01/02",
+		model.DateDM:  "02/01",
+	}[format]
+	if layout == "" {
+		format, layout = model.DateYMD, "2006-01-02"
+	}
+	return SayAs("date", string(format), t.Format(layout))
+}
+
+// SayAsTime reads the clock time of t, in 12 or 24 hour form.
+func SayAsTime(t time.Time, hour12 bool) string {
+	if hour12 {
+		return SayAs("time", "hms12", t.Format("3:04PM"))
+	}
+	return SayAs("time", "hms24", t.Format("15:04"))
+}
+
+func SayAsTelephone(number string) string { return SayAs("telephone", "", number) }
+func SayAsCardinal(n int64) string        { return SayAs("cardinal", "", strconv.FormatInt(n, 10)) }
+func SayAsOrdinal(n int64) string         { return SayAs("ordinal", "", strconv.FormatInt(n, 10)) }
+func SayAsAddress(address string) string  { return SayAs("address", "", address) }
+func SayAsSpellOut(text string) string    { return SayAs("spell-out", "", text) }
+
+// SayAsCurrency reads amount in the currency with the given ISO 4217 code, e.g. "TWD" or "USD".
+func SayAsCurrency(amount float64, code string) string {
+	return SayAs("currency", "", strconv.FormatFloat(amount, 'f', -1, 64)+" "+strings.ToUpper(code))
+}
+
+func SayAsFraction(numerator, denominator int) string {
+	return SayAs("fraction", "", fmt.Sprintf("%d/%d", numerator, denominator))
+}
+
+// localeDateFormat is the field order used for ambiguous numeric dates such as 03/04/2024.
+func localeDateFormat(locale model.Locale) model.DateFormat {
+	switch {
+	case locale == model.LocaleEnUS || locale == model.LocaleFilPH:
+		return model.DateMDY
+	case locale.Language() == "zh" || locale.Language() == "yue" || locale.Language() == "wuu",
+		locale == model.LocaleJaJP, locale == model.LocaleKoKR:
+		return model.DateYMD
+	}
+	return model.DateDMY
+}
+
+// currencySymbols maps a currency prefix to its ISO code. "$" and "¥" depend on the locale.
+var currencySymbols = map[string]string{
+	"NT$": "TWD", "US$": "USD", "HK$": "HKD", "S$": "SGD", "A$": "AUD", "C$": "CAD", "€": "EUR", "£": "GBP", "₩": "KRW",
+}
+
+func localeCurrency(symbol string, locale model.Locale) string {
+	if code, ok := currencySymbols[symbol]; ok {
+		return code
+	}
+	switch symbol {
+	case "$":
+		switch locale {
+		case model.LocaleZhTW:
+			return "TWD"
+		case model.LocaleZhHK:
+			return "HKD"
+		case model.LocaleEnAU:
+			return "AUD"
+		case model.LocaleEnCA, model.LocaleFrCA:
+			return "CAD"
+		}
+		return "USD"
+	case "¥", "￥":
+		if locale == model.LocaleJaJP {
+			return "JPY"
+		}
+		return "CNY"
+	}
+	return ""
+}
+
+// sayAsRule wraps every match of re in a <say-as> element built by render. render returns ""
+// to leave a match untouched.
+type sayAsRule struct {
+	re     *regexp.Regexp
+	render func(match []string) string
+}
+
+var (
+	reSayAsCurrency  = regexp.MustCompile(`(NT\$|US\$|HK\$|S\$|A\$|C\$|\$|€|£|¥|￥|₩)\s?(\d{1,3}(?:,\d{3})+|\d+)(\.\d+)?`)
+	reSayAsISODate   = regexp.MustCompile(`(\d{4})([-/.])(\d{1,2})[-/.](\d{1,2})`)
+	reSayAsShortDate = regexp.MustCompile(`(\d{2})\.(\d{1,2})\.(\d{1,2})`)
+	reSayAsSlashDate = regexp.MustCompile(`(\d{1,2})/(\d{1,2})/(\d{4})`)
+	reSayAsTime      = regexp.MustCompile(`([01]?\d|2[0-3]):([0-5]\d)(?::[0-5]\d)?(\s?[AaPp]\.?[Mm]\.?)?`)
+	reSayAsTWPhone   = regexp.MustCompile(`\+886[- ]?\d{1,2}[- ]?\d{3,4}[- ]?\d{3,4}|09\d{2}-?\d{3}-?\d{3}|\(0\d{1,2}\)\s?\d{3,4}-\d{4}|0[2-8]\d?-\d{3,4}-\d{4}`)
+	reSayAsUSPhone   = regexp.MustCompile(`(?:\+1[- ]?)?(?:\(\d{3}\)\s?|\d{3}[-.])\d{3}[-.]\d{4}`)
+	reSayAsIntlPhone = regexp.MustCompile(`\+\d{1,3}(?:[- ]\d{2,4}){2,4}`)
+	reSayAsThousands = regexp.MustCompile(`\d{1,3}(?:,\d{3})+(?:\.\d+)?`)
+	reSayAsOrdinal   = regexp.MustCompile(`(\d+)(?:st|nd|rd|th)`)
+	reSayAsCode      = regexp.MustCompile(`(?:[A-Z]+\d|\d+[A-Z])[A-Z0-9-]*`)
+)
+
+// sayAsRules returns the detectors applied to plain text for locale, most specific first.
+func sayAsRules(locale model.Locale) []sayAsRule {
+	rules := []sayAsRule{
+		{re: reSayAsCurrency, render: func(m []string) string {
+			code := localeCurrency(m[1], locale)
+			if code == "" {
+				return ""
+			}
+			return SayAs("currency", "", strings.ReplaceAll(m[2], ",", "")+m[3]+" "+code)
+		}},
+	}
+
+	switch locale {
+	case model.LocaleZhTW:
+		rules = append(rules, sayAsRule{re: reSayAsTWPhone, render: renderTelephone})
+	case model.LocaleEnUS, model.LocaleEnCA:
+		rules = append(rules, sayAsRule{re: reSayAsUSPhone, render: renderTelephone})
+	}
+	rules = append(rules,
+		sayAsRule{re: reSayAsIntlPhone, render: renderTelephone},
+		sayAsRule{re: reSayAsISODate, render: func(m []string) string {
+			return renderDate(m[0], model.DateYMD, m[3], m[4])
+		}},
+		sayAsRule{re: reSayAsShortDate, render: func(m []string) string {
+			return renderDate(m[0], model.DateYMD, m[2], m[3])
+		}},
+		sayAsRule{re: reSayAsSlashDate, render: func(m []string) string {
+			format := localeDateFormat(locale)
+			if format == model.DateMDY {
+				return renderDate(m[0], format, m[1], m[2])
+			}
+			return renderDate(m[0], model.DateDMY, m[2], m[1])
+		}},
+		sayAsRule{re: reSayAsTime, render: func(m []string) string {
+			if m[3] != "" {
+				return SayAs("time", "hms12", m[0])
+			}
+			return SayAs("time", "hms24", m[0])
+		}},
+		sayAsRule{re: reSayAsThousands, render: func(m []string) string {
+			return SayAs("cardinal", "", strings.ReplaceAll(m[0], ",", ""))
+		}},
+		sayAsRule{re: reSayAsCode, render: func(m []string) string {
+			if len(m[0]) < 5 {
+				return ""
+			}
+			return SayAs("spell-out", "", m[0])
+		}},
+	)
+	if strings.HasPrefix(locale.String(), "en-") {
+		rules = append(rules, sayAsRule{re: reSayAsOrdinal, render: func(m []string) string {
+			return SayAs("ordinal", "", m[1])
+		}})
+	}
+	return rules
+}
+
+func renderTelephone(m []string) string {
+	return SayAs("telephone", "", m[0])
+}
+
+// renderDate wraps a date whose month and day are valid, otherwise it leaves the match alone.
+func renderDate(match string, format model.DateFormat, month, day string) string {
+	m, _ := strconv.Atoi(month)
+	d, _ := strconv.Atoi(day)
+	if m < 1 || m > 12 || d < 1 || d > 31 {
+		return ""
+	}
+	return SayAs("date", string(format), match)
+}
+
+// detectSayAs applies the locale's say-as detectors to text that is not already inside markup.
+func detectSayAs(text string, locale model.Locale) string {
+	for _, rule := range sayAsRules(locale) {
+		text = replaceOutsideTags(text, rule.re, rule.render)
+	}
+	return text
+}
+
+var reMarkupSpan = regexp.MustCompile(`<say-as[^>]*>.*?</say-as>|<phoneme[^>]*>.*?</phoneme>|<sub[^>]*>.*?</sub>|<[^>]+>|&[a-zA-Z#0-9]+;`)
+
+// replaceOutsideTags replaces matches of re that are neither inside an element or entity nor glued
+// to surrounding letters or digits. render returns "" to keep a match unchanged.
+func replaceOutsideTags(text string, re *regexp.Regexp, render func(match []string) string) string {
+	protected := reMarkupSpan.FindAllStringIndex(text, -1)
+	var b strings.Builder
+	last := 0
+	for _, loc := range re.FindAllStringSubmatchIndex(text, -1) {
+		start, end := loc[0], loc[1]
+		if overlaps(protected, start, end) || !isTokenBoundary(text, start, end) {
+			continue
+		}
+		match := make([]string, len(loc)/2)
+		for i := range match {
+			if loc[2*i] >= 0 {
+				match[i] = text[loc[2*i]:loc[2*i+1]]
+			}
+		}
+		replacement := render(match)
+		if replacement == "" {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(replacement)
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+func overlaps(spans [][]int, start, end int) bool {
+	for _, span := range spans {
+		if start < span[1] && end > span[0] {
+			return true
+		}
+	}
+	return false
+}
+
+// isTokenBoundary reports whether text[start:end] is not part of a longer ASCII word or number.
+func isTokenBoundary(text string, start, end int) bool {
+	if r, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && isASCIIAlnum(r) {
+		return false
+	}
+	if r, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && isASCIIAlnum(r) {
+		return false
+	}
+	return true
+}
+
+func isASCIIAlnum(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_detectSayAs(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		locale model.Locale
+		want   string
+	}{
+		{
+			name:   "NT$ price",
+			text:   "總金額NT$1,280元",
+			locale: model.LocaleZhTW,
+			want:   `總金額<say-as interpret-as="currency">1280 TWD</say-as>元`,
+		},
+		{
+			name:   "dollar depends on locale",
+			text:   "It costs $12.50 today",
+			locale: model.LocaleEnUS,
+			want:   `It costs <say-as interpret-as="currency">12.50 USD</say-as> today`,
+		},
+		{
+			name:   "Taiwanese phone numbers",
+			text:   "請撥0912-345-678或(02)2345-6789",
+			locale: model.LocaleZhTW,
+			want:   `請撥<say-as interpret-as="telephone">0912-345-678</say-as>或<say-as interpret-as="telephone">(02)2345-6789</say-as>`,
+		},
+		{
+			name:   "US phone number",
+			text:   "Call (555) 123-4567 now",
+			locale: model.LocaleEnUS,
+			want:   `Call <say-as interpret-as="telephone">(555) 123-4567</say-as> now`,
+		},
+		{
+			name:   "slash date per locale",
+			text:   "Due 03/04/2024",
+			locale: model.LocaleEnUS,
+			want:   `Due <say-as interpret-as="date" format="mdy">03/04/2024</say-as>`,
+		},
+		{
+			name:   "slash date day first",
+			text:   "Due 23/04/2024",
+			locale: model.LocaleEnGB,
+			want:   `Due <say-as interpret-as="date" format="dmy">23/04/2024</say-as>`,
+		},
+		{
+			name:   "time and ordinal",
+			text:   "Meet at 9:30 PM on the 2nd floor, or 14:00",
+			locale: model.LocaleEnUS,
+			want: `Meet at <say-as interpret-as="time" format="hms12">9:30 PM</say-as> on the <say-as interpret-as="ordinal">2</say-as> floor, ` +
+				`or <say-as interpret-as="time" format="hms24">14:00</say-as>`,
+		},
+		{
+			name:   "order number and thousands",
+			text:   "訂單TW20240315共1,234件",
+			locale: model.LocaleZhTW,
+			want:   `訂單<say-as interpret-as="spell-out">TW20240315</say-as>共<say-as interpret-as="cardinal">1234</say-as>件`,
+		},
+		{
+			name:   "existing markup is left alone",
+			text:   `<say-as interpret-as="digits">2024-03-15</say-as> 2024-13-01`,
+			locale: model.LocaleZhTW,
+			want:   `<say-as interpret-as="digits">2024-03-15</say-as> 2024-13-01`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, detectSayAs(tt.text, tt.locale))
+		})
+	}
+}
+
+func TestSayAsHelpers(t *testing.T) {
+	day := time.Date(2024, 3, 15, 14, 5, 0, 0, time.UTC)
+	assert.Equal(t, `<say-as interpret-as="date" format="mdy">03/15/2024</say-as>`, SayAsDate(day, model.DateMDY))
+	assert.Equal(t, `<say-as interpret-as="date" format="ymd">2024-03-15</say-as>`, SayAsDate(day, ""))
+	assert.Equal(t, `<say-as interpret-as="time" format="hms12">2:05PM</say-as>`, SayAsTime(day, true))
+	assert.Equal(t, `<say-as interpret-as="currency">99.9 TWD</say-as>`, SayAsCurrency(99.9, "twd"))
+	assert.Equal(t, `<say-as interpret-as="fraction">3/4</say-as>`, SayAsFraction(3, 4))
+	assert.Equal(t, `<say-as interpret-as="address">台北市信義路5段7號</say-as>`, SayAsAddress("台北市信義路5段7號"))
+	assert.Equal(t, `<say-as interpret-as="spell-out">A&amp;B</say-as>`, SayAsSpellOut("A&B"))
+	assert.Equal(t, `<say-as interpret-as="date&quot; x=&quot;1" format="&lt;ymd&gt;">1</say-as>`, SayAs(`date" x="1`, "<ymd>", "1"))
+}
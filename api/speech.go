@@ -5,14 +5,18 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"strings"
+	"time"
 
 	"github.com/barkingdog-ai/azure-tts/model"
-	"github.com/barkingdog-ai/azure-tts/utils"
+	"github.com/barkingdog-ai/azure-tts/usage"
+	"go.opentelemetry.io/otel/metric"
 )
 
 type SpeechInterface interface {
 	TextToSpeech(ctx context.Context, req *model.TextToSpeechRequest) ([]byte, error)
+	TextToSpeechStream(ctx context.Context, req *model.TextToSpeechRequest) (io.ReadCloser, error)
 	SpeechToText(ctx context.Context, req model.SpeechToTextReq) (*model.SpeechToTextResp, error)
 	CorrectHomophones(req *model.TextToSpeechRequest)
 }
@@ -21,44 +25,92 @@ func (az *AzureTTSClient) TextToSpeech(ctx context.Context,
 	request *model.TextToSpeechRequest,
 ) ([]byte, error) {
 	respData := make([]byte, 0)
-	rate, _ := utils.ConvertStringToFloat32(request.Rate)
-	pitch, _ := utils.ConvertStringToFloat32(request.Pitch)
-	rateValue := (rate - 1) * 100
-	pitchValue := (pitch - 1) * 50
-	az.CorrectHomophones(request)
-	v := voiceXML(
-		request.SpeechText,
-		request.VoiceName,
-		request.Locale,
-		request.Gender,
-		utils.ConvertFloat32ToString(rateValue)+"%",
-		utils.ConvertFloat32ToString(pitchValue)+"%",
-		request.Style,
-	)
-
-	req, err := az.newTTSRequest(ctx, "POST", az.TextToSpeechURL, bytes.NewBufferString(v), request.AudioOutput)
+	stream, err := az.TextToSpeechStream(ctx, request)
 	if err != nil {
-		return respData, fmt.Errorf("tts request error %v", err)
+		return respData, err
 	}
+	defer stream.Close()
 
-	resp, err := az.performRequest(req)
+	respData, err = io.ReadAll(stream)
 	if err != nil {
-		return respData, fmt.Errorf("perform request error %v", err)
+		return respData, fmt.Errorf("perform request error %w", err)
+	}
+
+	return respData, nil
+}
+
+// TextToSpeechStream starts synthesizing request and returns the audio as it arrives from the service.
+// The caller must close the returned reader.
+func (az *AzureTTSClient) TextToSpeechStream(ctx context.Context,
+	request *model.TextToSpeechRequest,
+) (_ io.ReadCloser, err error) {
+	if err := az.begin(); err != nil {
+		return nil, err
 	}
+	// the call stays in flight until the caller closes the audio stream
+	defer func() {
+		if err != nil {
+			az.inflight.Done()
+		}
+	}()
 
-	defer resp.Body.Close()
+	if request.VoiceName == "" {
+		if request, err = az.withSelectedVoice(ctx, request); err != nil {
+			return nil, err
+		}
+	}
+	style, defaults := az.resolveStyle(ctx, request)
+	az.CorrectHomophones(request)
 
-	respData, err = io.ReadAll(resp.Body)
+	ctx, c := az.startCall(ctx, "TextToSpeech",
+		attrVoice.String(request.VoiceName),
+		attrLocale.String(request.Locale.String()),
+		attrOutputFormat.String(request.AudioOutput.String()))
+	defer func() {
+		if err != nil {
+			c.end(ctx, err)
+		}
+	}()
+	az.Logger().LogAttrs(ctx, slog.LevelDebug, "synthesizing speech",
+		slog.String("voice", request.VoiceName),
+		slog.String("locale", request.Locale.String()),
+		slog.String("output_format", request.AudioOutput.String()),
+		az.speechTextAttr(request.SpeechText))
+
+	v, err := renderSSML(request, style, defaults)
 	if err != nil {
-		return respData, fmt.Errorf("perform request error %v", err)
+		return nil, err
 	}
+	v = az.withLexicons(v)
+	characters := usage.BillableCharacters(v)
+	c.span.SetAttributes(attrCharacters.Int(characters))
 
-	return respData, nil
+	req, err := az.newTTSRequest(ctx, "POST", az.TextToSpeechURL, bytes.NewBufferString(v), request.AudioOutput)
+	if err != nil {
+		return nil, fmt.Errorf("tts request error %w", err)
+	}
+
+	resp, err := az.performRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("perform request error %w", invalidSSML(err))
+	}
+	c.tel.characters.Add(ctx, int64(characters), metric.WithAttributes(c.with(attrVoice.String(request.VoiceName))...))
+	az.recordUsage(ctx, usage.Record{Kind: usage.KindForVoice(request.VoiceName), Voice: request.VoiceName, Characters: characters})
+	return &instrumentedBody{ReadCloser: resp.Body, ctx: ctx, call: c, voice: request.VoiceName, done: az.inflight.Done}, nil
 }
 
+// SpeechToText recognizes the speech in the audio of request. When no speech is recognized the
+// response is returned together with an error matching model.ErrNoMatch.
 func (az *AzureTTSClient) SpeechToText(ctx context.Context,
 	request model.SpeechToTextReq,
-) (*model.SpeechToTextResp, error) {
+) (_ *model.SpeechToTextResp, err error) {
+	if err := az.begin(); err != nil {
+		return nil, err
+	}
+	defer az.inflight.Done()
+	ctx, c := az.startCall(ctx, "SpeechToText", attrLocale.String(request.Language))
+	defer func() { c.end(ctx, err) }()
+
 	url := fmt.Sprintf("%s?language=%s", az.SpeechToTextURL, request.Language)
 
 	payload, err := createFilePayload(request)
@@ -68,23 +120,113 @@ func (az *AzureTTSClient) SpeechToText(ctx context.Context,
 
 	req, err := az.newSTTRequest(ctx, "POST", url, payload)
 	if err != nil {
-		return nil, fmt.Errorf("STT request error: %v", err)
+		return nil, fmt.Errorf("STT request error: %w", err)
 	}
 
 	resp, err := az.performRequest(req)
 	if err != nil {
-		return nil, fmt.Errorf("perform request error %v", err)
+		return nil, fmt.Errorf("perform request error %w", err)
 	}
 
 	output := new(model.SpeechToTextResp)
 	if err := getResponseObject(resp, output); err != nil {
 		return nil, err
 	}
+	// Duration is given in 100-nanosecond ticks
+	az.recordUsage(ctx, usage.Record{Kind: usage.KindSTT, AudioDuration: time.Duration(output.Duration) * 100})
+	if output.RecognitionStatus != "" && output.RecognitionStatus != "Success" {
+		// the response is returned as well, it still carries the offset and duration
+		return output, &model.RecognitionError{Status: output.RecognitionStatus}
+	}
 	return output, nil
 }
 
 func (az *AzureTTSClient) CorrectHomophones(req *model.TextToSpeechRequest) {
-	for _, homophone := range req.Homophones {
-		req.SpeechText = strings.ReplaceAll(req.SpeechText, homophone.TargetText, homophone.ReplaceText)
+	req.SpeechText = correctHomophones(req.SpeechText, req.Homophones)
+}
+
+func correctHomophones(text string, homophones []model.Homophones) string {
+	for _, homophone := range homophones {
+		text = strings.ReplaceAll(text, homophone.TargetText, homophone.ReplaceText)
+	}
+	return text
+}
+
+// BuildSSML renders the SSML document TextToSpeech would send for request without contacting the
+// service. A StyleProfile is resolved without the voice catalog, i.e. to its primary style.
+func BuildSSML(request *model.TextToSpeechRequest) (string, error) {
+	style := request.Style
+	var defaults *model.Prosody
+	if profile := request.StyleProfile; profile != nil {
+		if style == nil {
+			style = profile.Resolve(nil)
+		}
+		defaults = profile.Prosody
+	}
+
+	corrected := *request
+	corrected.SpeechText = correctHomophones(request.SpeechText, request.Homophones)
+	return renderSSML(&corrected, style, defaults)
+}
+
+// renderSSML validates the prosody of request and renders its SSML payload.
+func renderSSML(request *model.TextToSpeechRequest, style *model.TTSStyle, defaults *model.Prosody) (string, error) {
+	prosody, err := requestProsody(request, defaults)
+	if err != nil {
+		return "", err
+	}
+	if request.InlineMarkup {
+		return markupXML(request.SpeechText, request.VoiceName, request.Locale, request.Gender, prosody, style,
+			request.Languages)
+	}
+	return voiceXML(
+		request.SpeechText,
+		request.VoiceName,
+		request.Locale,
+		request.Gender,
+		prosody,
+		style,
+		request.Languages,
+	), nil
+}
+
+// resolveStyle returns the style to synthesize request with and the prosody defaults of its style
+// profile. An explicit Style wins; otherwise the StyleProfile is resolved against the voice catalog.
+// If the catalog can't be fetched the profile's primary style is used.
+func (az *AzureTTSClient) resolveStyle(ctx context.Context,
+	request *model.TextToSpeechRequest,
+) (*model.TTSStyle, *model.Prosody) {
+	profile := request.StyleProfile
+	if profile == nil {
+		return request.Style, nil
+	}
+	if request.Style != nil {
+		return request.Style, profile.Prosody
+	}
+
+	voice, err := az.lookupVoice(ctx, request.VoiceName)
+	if err != nil {
+		az.Logger().DebugContext(ctx, "style profile resolved without voice catalog",
+			slog.String("profile", profile.Name), slog.Any("error", err))
+		voice = nil
+	}
+	return profile.Resolve(voice), profile.Prosody
+}
+
+// requestProsody returns the validated prosody of request, converting the deprecated Rate and Pitch
+// fields when Prosody is not set and filling unset values from defaults.
+func requestProsody(request *model.TextToSpeechRequest, defaults *model.Prosody) (*model.Prosody, error) {
+	prosody := request.Prosody
+	if prosody == nil {
+		legacy, err := model.ProsodyFromLegacy(request.Rate, request.Pitch)
+		if err != nil {
+			return nil, err
+		}
+		prosody = legacy
+	}
+	prosody = prosody.Merge(defaults)
+	if err := prosody.Validate(); err != nil {
+		return nil, err
 	}
+	return prosody, nil
 }
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+	"github.com/barkingdog-ai/azure-tts/usage"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// instrumentationName identifies the tracer and meter of the client.
+const instrumentationName = "github.com/barkingdog-ai/azure-tts"
+
+// Attribute keys set on spans and metrics.
+const (
+	attrOperation    = attribute.Key("azuretts.operation")
+	attrRegion       = attribute.Key("azuretts.region")
+	attrVoice        = attribute.Key("azuretts.voice")
+	attrLocale       = attribute.Key("azuretts.locale")
+	attrOutputFormat = attribute.Key("azuretts.output_format")
+	attrCharacters   = attribute.Key("azuretts.characters")
+	attrAudioBytes   = attribute.Key("azuretts.audio_bytes")
+	attrErrorType    = attribute.Key("azuretts.error.type")
+	attrHTTPStatus   = attribute.Key("http.response.status_code")
+)
+
+// telemetry holds the instruments used to trace and measure the calls to Azure.
+type telemetry struct {
+	tracer     trace.Tracer
+	duration   metric.Float64Histogram
+	firstByte  metric.Float64Histogram
+	characters metric.Int64Counter
+	audioBytes metric.Int64Counter
+	errors     metric.Int64Counter
+}
+
+// noopTelemetry is used until WithTracerProvider or WithMeterProvider is given.
+var noopTelemetry, _ = newTelemetry(tracenoop.NewTracerProvider(), metricnoop.NewMeterProvider())
+
+func newTelemetry(tp trace.TracerProvider, mp metric.MeterProvider) (*telemetry, error) {
+	if tp == nil {
+		tp = tracenoop.NewTracerProvider()
+	}
+	if mp == nil {
+		mp = metricnoop.NewMeterProvider()
+	}
+	meter := mp.Meter(instrumentationName)
+
+	t := &telemetry{tracer: tp.Tracer(instrumentationName)}
+	var err error
+	if t.duration, err = meter.Float64Histogram("azuretts.request.duration",
+		metric.WithDescription("Duration of requests to the Azure speech service"), metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	if t.firstByte, err = meter.Float64Histogram("azuretts.tts.time_to_first_byte",
+		metric.WithDescription("Time from sending a synthesis request to receiving the first audio byte"), metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	if t.characters, err = meter.Int64Counter("azuretts.tts.characters",
+		metric.WithDescription("Characters sent for synthesis"), metric.WithUnit("{character}")); err != nil {
+		return nil, err
+	}
+	if t.audioBytes, err = meter.Int64Counter("azuretts.tts.audio",
+		metric.WithDescription("Audio received from synthesis"), metric.WithUnit("By")); err != nil {
+		return nil, err
+	}
+	if t.errors, err = meter.Int64Counter("azuretts.errors",
+		metric.WithDescription("Failed requests to the Azure speech service"), metric.WithUnit("{error}")); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (az *AzureTTSClient) telemetry() *telemetry {
+	if az.tel == nil {
+		return noopTelemetry
+	}
+	return az.tel
+}
+
+// region returns the Azure region of the client.
+func (az *AzureTTSClient) region() string {
+	return string(az.Region)
+}
+
+// call is an instrumented request to Azure, from start until end is called.
+type call struct {
+	tel   *telemetry
+	span  trace.Span
+	start time.Time
+	// attrs are shared by the span and the metrics, so they must stay low cardinality
+	attrs []attribute.KeyValue
+}
+
+// startCall starts the span of operation op. attrs are set on the span only.
+func (az *AzureTTSClient) startCall(ctx context.Context, op string, attrs ...attribute.KeyValue) (context.Context, *call) {
+	tel := az.telemetry()
+	c := &call{
+		tel:   tel,
+		start: time.Now(),
+		attrs: []attribute.KeyValue{attrOperation.String(op), attrRegion.String(az.region())},
+	}
+	ctx, c.span = tel.tracer.Start(ctx, "azuretts."+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(c.attrs...),
+		trace.WithAttributes(attrs...))
+	return ctx, c
+}
+
+// with returns the metric attributes of the call extended by extra.
+func (c *call) with(extra ...attribute.KeyValue) []attribute.KeyValue {
+	return append(c.attrs[:len(c.attrs):len(c.attrs)], extra...)
+}
+
+// end records the outcome of the call and ends its span.
+func (c *call) end(ctx context.Context, err error) {
+	attrs := c.attrs
+	if err != nil {
+		errType := "Error"
+		var apiErr model.APIError
+		var recognitionErr *model.RecognitionError
+		switch {
+		case errors.As(err, &apiErr):
+			errType = apiErr.Type
+			c.span.SetAttributes(attrHTTPStatus.Int(apiErr.StatusCode))
+		case errors.As(err, &recognitionErr):
+			errType = recognitionErr.Status
+		case errors.Is(err, model.ErrTimeout):
+			errType = "Timeout"
+		case errors.Is(err, model.ErrNetwork):
+			errType = "Network"
+		}
+		attrs = c.with(attrErrorType.String(errType))
+		c.span.SetAttributes(attrErrorType.String(errType))
+		c.span.RecordError(err)
+		c.span.SetStatus(codes.Error, err.Error())
+		c.tel.errors.Add(ctx, 1, metric.WithAttributes(attrs...))
+	}
+	c.tel.duration.Record(ctx, time.Since(c.start).Seconds(), metric.WithAttributes(attrs...))
+	c.span.End()
+}
+
+// recordStatus sets the HTTP status of the response on the span of the request.
+func recordStatus(ctx context.Context, status int) {
+	trace.SpanFromContext(ctx).SetAttributes(attrHTTPStatus.Int(status))
+}
+
+// instrumentedBody measures a synthesis response while it is read and ends its call on Close.
+type instrumentedBody struct {
+	io.ReadCloser
+	ctx     context.Context
+	call    *call
+	voice   string
+	n       int64
+	readErr error
+	closed  bool
+	// done is called once the body is closed
+	done func()
+}
+
+func (b *instrumentedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 && b.n == 0 {
+		b.call.tel.firstByte.Record(b.ctx, time.Since(b.call.start).Seconds(), metric.WithAttributes(b.call.attrs...))
+	}
+	b.n += int64(n)
+	if err != nil && err != io.EOF {
+		b.readErr = err
+	}
+	return n, err
+}
+
+func (b *instrumentedBody) Close() error {
+	err := b.ReadCloser.Close()
+	if b.closed {
+		return err
+	}
+	b.closed = true
+	b.call.span.SetAttributes(attrAudioBytes.Int64(b.n))
+	b.call.tel.audioBytes.Add(b.ctx, b.n, metric.WithAttributes(b.call.with(attrVoice.String(b.voice))...))
+	b.call.end(b.ctx, b.readErr)
+	if b.done != nil {
+		b.done()
+	}
+	return err
+}
+
+// recordUsage reports r to the usage meter, tagged with the tenant of ctx.
+func (az *AzureTTSClient) recordUsage(ctx context.Context, r usage.Record) {
+	if az.usageMeter == nil {
+		return
+	}
+	r.Tenant = usage.Tenant(ctx)
+	r.Time = time.Now()
+	az.usageMeter.Record(ctx, r)
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+	"github.com/barkingdog-ai/azure-tts/usage"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTelemetry(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/throttled" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":{"type":"Throttled","message":"slow down"}}`))
+			return
+		}
+		_, _ = w.Write([]byte("audio"))
+	}))
+	defer srv.Close()
+
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	az := &AzureTTSClient{HTTPClient: srv.Client(), TextToSpeechURL: srv.URL + "/tts"}
+	assert.NoError(t, WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))(az))
+	assert.NoError(t, WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))(az))
+
+	request := &model.TextToSpeechRequest{
+		SpeechText:  "你好嗎",
+		VoiceName:   "zh-TW-HsiaoChenNeural",
+		Locale:      model.LocaleZhTW,
+		AudioOutput: model.Audio16khz32kbitrateMonoMp3,
+	}
+	ssml, err := BuildSSML(request)
+	assert.NoError(t, err)
+	characters := int64(usage.BillableCharacters(ssml))
+
+	agg := usage.NewAggregator()
+	assert.NoError(t, WithUsageMeter(agg)(az))
+	audio, err := az.TextToSpeech(usage.WithTenant(context.Background(), "acme"), request)
+	assert.NoError(t, err)
+	assert.Equal(t, "audio", string(audio))
+
+	az.TextToSpeechURL = srv.URL + "/throttled"
+	_, err = az.TextToSpeech(context.Background(), request)
+	assert.Error(t, err)
+
+	assert.Equal(t, []usage.Summary{{Tenant: "acme", Kind: usage.KindNeuralTTS, Requests: 1, Characters: characters}}, agg.Usage())
+
+	ended := spans.Ended()
+	assert.Len(t, ended, 2)
+	attrs := func(i int) map[attribute.Key]attribute.Value {
+		m := map[attribute.Key]attribute.Value{}
+		for _, kv := range ended[i].Attributes() {
+			m[kv.Key] = kv.Value
+		}
+		return m
+	}
+	assert.Equal(t, "azuretts.TextToSpeech", ended[0].Name())
+	assert.Equal(t, characters, attrs(0)[attrCharacters].AsInt64())
+	assert.Equal(t, int64(5), attrs(0)[attrAudioBytes].AsInt64())
+	assert.Equal(t, int64(200), attrs(0)[attrHTTPStatus].AsInt64())
+	assert.Equal(t, "audio-16khz-32kbitrate-mono-mp3", attrs(0)[attrOutputFormat].AsString())
+	assert.Equal(t, int64(429), attrs(1)[attrHTTPStatus].AsInt64())
+	assert.Equal(t, "Throttled", attrs(1)[attrErrorType].AsString())
+
+	var rm metricdata.ResourceMetrics
+	assert.NoError(t, reader.Collect(context.Background(), &rm))
+	sums := map[string]int64{}
+	counts := map[string]uint64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, dp := range data.DataPoints {
+					sums[m.Name] += dp.Value
+				}
+			case metricdata.Histogram[float64]:
+				for _, dp := range data.DataPoints {
+					counts[m.Name] += dp.Count
+				}
+			}
+		}
+	}
+	assert.Equal(t, characters, sums["azuretts.tts.characters"])
+	assert.Equal(t, int64(5), sums["azuretts.tts.audio"])
+	assert.Equal(t, int64(1), sums["azuretts.errors"])
+	assert.Equal(t, uint64(2), counts["azuretts.request.duration"])
+	assert.Equal(t, uint64(1), counts["azuretts.tts.time_to_first_byte"])
+}
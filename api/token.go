@@ -4,29 +4,125 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
+	"time"
 )
 
+// tokenLifetime is how long a token issued for the subscription key is used. Azure tokens are valid
+// for 10 minutes.
+const tokenLifetime = 9 * time.Minute
+
 type TokenInterface interface {
 	RefreshToken(ctx context.Context) error
 }
 
-func (az *AzureTTSClient) RefreshToken(ctx context.Context) error {
-	req, err := az.newTokenRequest(ctx, "POST", az.TokenRefreshURL, nil)
-	if err != nil {
+// TokenSource supplies bearer tokens minted outside of the client. Token is called for every request,
+// so implementations should cache tokens until they are about to expire.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticToken is a TokenSource always returning the same token.
+type StaticToken string
+
+func (t StaticToken) Token(context.Context) (string, error) {
+	return string(t), nil
+}
+
+// NeedsInitialToken reports whether the client must fetch a token before it is used, i.e. it
+// exchanges its subscription key for tokens and WithLazyAuth was not given.
+func (az *AzureTTSClient) NeedsInitialToken() bool {
+	return az.tokenSource == nil && !az.lazyAuth
+}
+
+// RefreshesToken reports whether the client exchanges its subscription key for tokens that must be
+// refreshed in the background.
+func (az *AzureTTSClient) RefreshesToken() bool {
+	return az.tokenSource == nil && az.SubscriptionKey != ""
+}
+
+// RefreshToken fetches a new token, from the TokenSource when one was given and otherwise by
+// exchanging the subscription key.
+func (az *AzureTTSClient) RefreshToken(ctx context.Context) (err error) {
+	if err := az.begin(); err != nil {
 		return err
 	}
+	defer az.inflight.Done()
+	ctx, c := az.startCall(ctx, "RefreshToken")
+	defer func() {
+		if err != nil {
+			az.Logger().WarnContext(ctx, "token refresh failed", slog.Any("error", err))
+		}
+		c.end(ctx, err)
+	}()
 
-	resp, err := az.performReq(req)
+	token, err := az.fetchToken(ctx)
 	if err != nil {
 		return err
 	}
+
+	az.tokenMu.Lock()
+	az.AccessToken = token
+	az.tokenExpiry = time.Now().Add(tokenLifetime)
+	az.tokenMu.Unlock()
+	az.Logger().DebugContext(ctx, "token refreshed")
+	return nil
+}
+
+func (az *AzureTTSClient) fetchToken(ctx context.Context) (string, error) {
+	if az.tokenSource != nil {
+		return az.tokenSource.Token(ctx)
+	}
+
+	req, err := az.newTokenRequest(ctx, "POST", az.TokenRefreshURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := az.performRequest(req)
+	if err != nil {
+		return "", err
+	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+		return "", fmt.Errorf("failed to read response body: %w", err)
 	}
+	return string(body), nil
+}
 
-	az.AccessToken = string(body)
-	return nil
+// bearerToken returns the token to authorize a request with. Tokens from a TokenSource are requested
+// every time; tokens for the subscription key are fetched on first use and again once they expired,
+// so a client built with WithLazyAuth or whose background refresh failed recovers on its own.
+// A token set directly on AccessToken is used as is.
+func (az *AzureTTSClient) bearerToken(ctx context.Context) (string, error) {
+	if az.tokenSource != nil {
+		return az.tokenSource.Token(ctx)
+	}
+
+	token, stale := az.currentToken()
+	if !stale || az.SubscriptionKey == "" {
+		return token, nil
+	}
+
+	// concurrent callers finding the token stale wait for the first one's refresh instead of each
+	// fetching a token
+	az.tokenRefreshMu.Lock()
+	defer az.tokenRefreshMu.Unlock()
+	if token, stale = az.currentToken(); !stale {
+		return token, nil
+	}
+	if err := az.RefreshToken(ctx); err != nil {
+		return "", err
+	}
+	token, _ = az.currentToken()
+	return token, nil
+}
+
+// currentToken returns the access token and whether it is missing or expired.
+func (az *AzureTTSClient) currentToken() (string, bool) {
+	az.tokenMu.Lock()
+	defer az.tokenMu.Unlock()
+	token, expiry := az.AccessToken, az.tokenExpiry
+	return token, token == "" || (!expiry.IsZero() && time.Now().After(expiry))
 }
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBearerToken(t *testing.T) {
+	var issued atomic.Int32
+	var auth atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/issueToken") {
+			n := issued.Add(1)
+			_, _ = w.Write([]byte("token-" + string(rune('0'+n))))
+			return
+		}
+		auth.Store(r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte("audio"))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name       string
+		options    []ClientOption
+		expired    bool
+		wantAuth   string
+		wantIssued int32
+	}{
+		{name: "lazy token fetched on first use", options: []ClientOption{WithLazyAuth()}, wantAuth: "Bearer token-1", wantIssued: 1},
+		{name: "expired token refreshed", options: []ClientOption{WithLazyAuth()}, expired: true, wantAuth: "Bearer token-2", wantIssued: 2},
+		{name: "static token", options: []ClientOption{WithStaticToken("minted")}, wantAuth: "Bearer minted", wantIssued: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issued.Store(0)
+			az := &AzureTTSClient{
+				SubscriptionKey: "key",
+				HTTPClient:      srv.Client(),
+				TextToSpeechURL: srv.URL + "/cognitiveservices/v1",
+				TokenRefreshURL: srv.URL + "/sts/v1.0/issueToken",
+				voices:          []model.VoiceListResponse{{ShortName: "a"}},
+			}
+			for _, o := range tt.options {
+				assert.NoError(t, o(az))
+			}
+			assert.False(t, az.NeedsInitialToken())
+			if tt.expired {
+				assert.NoError(t, az.RefreshToken(context.Background()))
+				az.tokenExpiry = time.Now().Add(-time.Second)
+			}
+
+			_, err := az.TextToSpeech(context.Background(), &model.TextToSpeechRequest{
+				SpeechText: "你好",
+				VoiceName:  "zh-TW-HsiaoChenNeural",
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantAuth, auth.Load())
+			assert.Equal(t, tt.wantIssued, issued.Load())
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	var key, auth atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key.Store(r.Header.Get("Ocp-Apim-Subscription-Key"))
+		auth.Store(r.Header.Get("Authorization"))
+		if strings.HasSuffix(r.URL.Path, "/voices/list") {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"RecognitionStatus":"Success","DisplayText":"你好"}`))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		key      string
+		options  []ClientOption
+		wantKey  string
+		wantAuth string
+	}{
+		{name: "subscription key", key: "key", wantKey: "key"},
+		{name: "token source without key", options: []ClientOption{WithStaticToken("aad#res#token")}, wantAuth: "Bearer aad#res#token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			az := &AzureTTSClient{
+				SubscriptionKey:     tt.key,
+				HTTPClient:          srv.Client(),
+				VoiceServiceListURL: srv.URL + "/cognitiveservices/voices/list",
+				SpeechToTextURL:     srv.URL + "/speech/recognition/conversation/cognitiveservices/v1",
+			}
+			for _, o := range tt.options {
+				assert.NoError(t, o(az))
+			}
+
+			_, err := az.VoiceList(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantKey, key.Load())
+			assert.Equal(t, tt.wantAuth, auth.Load())
+
+			_, err = az.SpeechToText(context.Background(), model.SpeechToTextReq{Reader: strings.NewReader("RIFF")})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantKey, key.Load())
+			assert.Equal(t, tt.wantAuth, auth.Load())
+		})
+	}
+}
+
+func TestBearerTokenCoalescesRefreshes(t *testing.T) {
+	var issued atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		issued.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte("token"))
+	}))
+	defer srv.Close()
+
+	az := &AzureTTSClient{SubscriptionKey: "key", HTTPClient: srv.Client(), TokenRefreshURL: srv.URL, lazyAuth: true}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := az.bearerToken(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, "token", token)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), issued.Load(), "a burst of requests fetches one token")
+}
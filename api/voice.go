@@ -2,10 +2,21 @@ package api
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/barkingdog-ai/azure-tts/model"
 )
 
+// voiceCatalogTTL is how long the voice list fetched for synthesis-time lookups is reused, unless
+// changed with WithVoiceCatalogTTL.
+const voiceCatalogTTL = 24 * time.Hour
+
+// voiceCatalogRetryAfter is how long a failed voice list fetch is remembered before fetching again.
+const voiceCatalogRetryAfter = 30 * time.Second
+
 type VoiceInterface interface {
 	VoiceList(ctx context.Context) (*[]model.VoiceListResponse, error)
 }
@@ -14,7 +25,14 @@ func (az *AzureTTSClient) VoiceList(ctx context.Context) (*[]model.VoiceListResp
 	return az.VoiceListRequest(ctx)
 }
 
-func (az *AzureTTSClient) VoiceListRequest(ctx context.Context) (*[]model.VoiceListResponse, error) {
+func (az *AzureTTSClient) VoiceListRequest(ctx context.Context) (_ *[]model.VoiceListResponse, err error) {
+	if err := az.begin(); err != nil {
+		return nil, err
+	}
+	defer az.inflight.Done()
+	ctx, c := az.startCall(ctx, "VoiceList")
+	defer func() { c.end(ctx, err) }()
+
 	req, err := az.newRequest(ctx, "GET", az.VoiceServiceListURL, nil)
 	if err != nil {
 		return nil, err
@@ -30,3 +48,147 @@ func (az *AzureTTSClient) VoiceListRequest(ctx context.Context) (*[]model.VoiceL
 	}
 	return output, nil
 }
+
+// voiceCatalog returns the cached voice list, refreshing it once it is older than its TTL. Concurrent
+// callers share a single fetch, which runs outside the lock. After a failed fetch, the stale list, or
+// else the error, is returned for voiceCatalogRetryAfter before fetching again.
+func (az *AzureTTSClient) voiceCatalog(ctx context.Context) ([]model.VoiceListResponse, error) {
+	ttl := az.voicesTTL
+	if ttl <= 0 {
+		ttl = voiceCatalogTTL
+	}
+	for {
+		az.voicesMu.Lock()
+		if az.voices != nil && time.Since(az.voicesFetched) < ttl {
+			voices, fetched := az.voices, az.voicesFetched
+			az.voicesMu.Unlock()
+			az.Logger().DebugContext(ctx, "voice catalog cache hit", slog.Time("fetched", fetched))
+			return voices, nil
+		}
+		if az.voicesErr != nil && time.Since(az.voicesFailed) < voiceCatalogRetryAfter {
+			voices, err := az.voices, az.voicesErr
+			az.voicesMu.Unlock()
+			if voices != nil {
+				return voices, nil
+			}
+			return nil, err
+		}
+		if fetch := az.voicesFetch; fetch != nil {
+			az.voicesMu.Unlock()
+			select {
+			case <-fetch:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			// take the outcome of the fetch waited for, however short the TTL; an error is only its
+			// outcome while it is recent, a canceled fetch leaves an older one behind
+			az.voicesMu.Lock()
+			voices, err := az.voices, az.voicesErr
+			recent := time.Since(az.voicesFailed) < voiceCatalogRetryAfter
+			az.voicesMu.Unlock()
+			if voices != nil {
+				return voices, nil
+			}
+			if err != nil && recent {
+				return nil, err
+			}
+			continue
+		}
+		fetch := make(chan struct{})
+		az.voicesFetch = fetch
+		az.voicesMu.Unlock()
+
+		az.Logger().DebugContext(ctx, "voice catalog cache miss, fetching voice list")
+		voices, err := az.VoiceListRequest(ctx)
+
+		az.voicesMu.Lock()
+		az.voicesFetch = nil
+		close(fetch)
+		switch {
+		case err == nil:
+			az.voices, az.voicesFetched, az.voicesErr = *voices, time.Now(), nil
+		case ctx.Err() == nil:
+			// a canceled caller says nothing about the service, let the next caller fetch again
+			az.voicesErr, az.voicesFailed = err, time.Now()
+		}
+		stale := az.voices
+		az.voicesMu.Unlock()
+
+		if err != nil {
+			if stale != nil {
+				az.Logger().WarnContext(ctx, "voice list fetch failed, using the stale catalog", slog.Any("error", err))
+				return stale, nil
+			}
+			return nil, err
+		}
+		return *voices, nil
+	}
+}
+
+// lookupVoice finds a voice in the catalog by its short name (e.g. zh-TW-HsiaoChenNeural) or full name.
+func (az *AzureTTSClient) lookupVoice(ctx context.Context, name string) (*model.VoiceListResponse, error) {
+	voices, err := az.voiceCatalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range voices {
+		if strings.EqualFold(voices[i].ShortName, name) || strings.EqualFold(voices[i].Name, name) {
+			return &voices[i], nil
+		}
+	}
+	return nil, fmt.Errorf("voice %s not found in catalog", name)
+}
+
+// Locales returns the locales spoken by the voices of the catalog, including the secondary locales
+// of multilingual voices.
+func (az *AzureTTSClient) Locales(ctx context.Context) ([]model.Locale, error) {
+	voices, err := az.voiceCatalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return model.LocalesFromVoices(voices), nil
+}
+
+// SelectVoice picks the best voice of the catalog for a language tag such as "zh-Hant" or "en" and
+// returns it with the locale it speaks the text in. See model.SelectVoice.
+func (az *AzureTTSClient) SelectVoice(ctx context.Context, tag string,
+	prefs model.VoicePreferences,
+) (*model.VoiceListResponse, model.Locale, error) {
+	voices, err := az.voiceCatalog(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	return model.SelectVoice(voices, tag, prefs)
+}
+
+// withSelectedVoice returns a copy of request, which has no VoiceName, using the voice selected for
+// its Locale. The locale and gender of the copy are those of the selected voice.
+func (az *AzureTTSClient) withSelectedVoice(ctx context.Context,
+	request *model.TextToSpeechRequest,
+) (*model.TextToSpeechRequest, error) {
+	if request.Locale == "" {
+		return nil, fmt.Errorf("voice selection: %w: request has neither a voice name nor a locale", model.ErrNoVoice)
+	}
+	// Gender is not a preference: its zero value is GenderMale, so it can't tell male from unset
+	var prefs model.VoicePreferences
+	if request.VoicePreferences != nil {
+		prefs = *request.VoicePreferences
+	} else if request.Style != nil {
+		prefs.Style = request.Style.Style
+	}
+
+	voice, locale, err := az.SelectVoice(ctx, request.Locale.String(), prefs)
+	if err != nil {
+		return nil, fmt.Errorf("voice selection: %w", err)
+	}
+	az.Logger().DebugContext(ctx, "voice selected",
+		slog.String("voice", voice.ShortName), slog.String("requested_locale", request.Locale.String()))
+
+	selected := *request
+	selected.VoiceName = voice.ShortName
+	selected.Locale = locale
+	if gender, err := model.GenderString(voice.Gender); err == nil {
+		selected.Gender = gender
+	}
+	return &selected, nil
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVoiceSelection(t *testing.T) {
+	var ssml string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		ssml = string(body)
+		_, _ = w.Write([]byte("audio"))
+	}))
+	defer srv.Close()
+
+	az := &AzureTTSClient{
+		HTTPClient:      srv.Client(),
+		TextToSpeechURL: srv.URL,
+		voices: []model.VoiceListResponse{
+			{ShortName: "zh-HK-WanLungNeural", Locale: "zh-HK", Gender: "Male", VoiceType: "Neural"},
+			{ShortName: "zh-HK-HiuMaanNeural", Locale: "zh-HK", Gender: "Female", VoiceType: "Neural"},
+		},
+		voicesFetched: time.Now(),
+	}
+	request := &model.TextToSpeechRequest{SpeechText: "你好", Locale: "zh-Hant", Gender: model.GenderFemale}
+	audio, err := az.TextToSpeech(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, "audio", string(audio))
+	assert.Contains(t, ssml, `<voice xml:lang='zh-HK' xml:gender='Female' name='zh-HK-HiuMaanNeural'>`)
+	assert.Empty(t, request.VoiceName, "the request of the caller is left alone")
+
+	_, err = az.TextToSpeech(context.Background(), &model.TextToSpeechRequest{SpeechText: "你好", Locale: "zh-HK"})
+	require.NoError(t, err)
+	assert.Contains(t, ssml, `name='zh-HK-HiuMaanNeural'>`, "a locale-only request has no gender preference")
+
+	request.VoicePreferences = &model.VoicePreferences{Gender: "Male"}
+	_, err = az.TextToSpeech(context.Background(), request)
+	require.NoError(t, err)
+	assert.Contains(t, ssml, `<voice xml:lang='zh-HK' xml:gender='Male' name='zh-HK-WanLungNeural'>`)
+
+	request.VoicePreferences = &model.VoicePreferences{Gender: "Female", Voices: []string{"zh-HK-WanLungNeural"}}
+	_, err = az.TextToSpeech(context.Background(), request)
+	require.NoError(t, err)
+	assert.Contains(t, ssml, `<voice xml:lang='zh-HK' xml:gender='Male' name='zh-HK-WanLungNeural'>`)
+
+	_, err = az.TextToSpeech(context.Background(), &model.TextToSpeechRequest{SpeechText: "hi", Locale: "en"})
+	assert.ErrorIs(t, err, model.ErrNoVoice)
+	_, err = az.TextToSpeech(context.Background(), &model.TextToSpeechRequest{SpeechText: "hi"})
+	assert.ErrorIs(t, err, model.ErrNoVoice)
+}
+
+func TestVoiceCatalog(t *testing.T) {
+	var fetches atomic.Int32
+	var failing atomic.Bool
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		<-release
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"ShortName":"en-US-JennyNeural","Locale":"en-US"}]`))
+	}))
+	defer srv.Close()
+
+	az := &AzureTTSClient{HTTPClient: srv.Client(), VoiceServiceListURL: srv.URL, voicesTTL: time.Nanosecond}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			voices, err := az.voiceCatalog(context.Background())
+			assert.NoError(t, err)
+			assert.Len(t, voices, 1)
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), fetches.Load(), "concurrent callers share a fetch")
+
+	failing.Store(true)
+	for i := 0; i < 3; i++ {
+		voices, err := az.voiceCatalog(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, voices, 1, "the stale catalog is served while Azure fails")
+	}
+	assert.Equal(t, int32(2), fetches.Load(), "a failed fetch is not retried right away")
+
+	az.voicesMu.Lock()
+	az.voices = nil
+	az.voicesMu.Unlock()
+	_, err := az.voiceCatalog(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, int32(2), fetches.Load())
+}
+
+func TestVoiceCatalogAfterCanceledFetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"ShortName":"en-US-JennyNeural","Locale":"en-US"}]`))
+	}))
+	defer srv.Close()
+
+	// an old failure and a fetch in flight that ends canceled, leaving the failure behind
+	fetch := make(chan struct{})
+	az := &AzureTTSClient{
+		HTTPClient: srv.Client(), VoiceServiceListURL: srv.URL,
+		voicesErr: errors.New("old failure"), voicesFailed: time.Now().Add(-time.Hour), voicesFetch: fetch,
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := az.voiceCatalog(context.Background())
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	az.voicesMu.Lock()
+	az.voicesFetch = nil
+	close(fetch)
+	az.voicesMu.Unlock()
+
+	assert.NoError(t, <-done, "the waiter fetches again instead of returning the old error")
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+)
+
+// defaultVoiceWatchInterval is how often a VoiceWatcher without Interval polls the voice list.
+const defaultVoiceWatchInterval = time.Hour
+
+// VoiceWatcher polls the voice list and reports added and removed voices, speaking style changes
+// and status changes such as Preview to GA.
+type VoiceWatcher struct {
+	Client *AzureTTSClient
+	// Interval between polls, one hour when zero.
+	Interval time.Duration
+	// Baseline is the voice list the first poll is compared with, e.g. a saved snapshot. When nil,
+	// the first poll only sets the baseline.
+	Baseline []model.VoiceListResponse
+	// OnChange is called with the changes found by a poll, if there are any.
+	OnChange func([]model.VoiceChange)
+	// OnError is called when a poll fails. Failures are logged when it is nil.
+	OnError func(error)
+}
+
+// WatchVoices polls the voice list every interval and calls onChange with the changes until ctx is
+// done or the client is closed. See VoiceWatcher.
+func (az *AzureTTSClient) WatchVoices(ctx context.Context, interval time.Duration,
+	onChange func([]model.VoiceChange),
+) error {
+	w := &VoiceWatcher{Client: az, Interval: interval, OnChange: onChange}
+	return w.Run(ctx)
+}
+
+// Run polls right away and then every Interval until ctx is done, returning its error, or the client
+// is closed, returning nil. Every successful poll also refreshes the voice catalog of the client.
+func (w *VoiceWatcher) Run(ctx context.Context) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = defaultVoiceWatchInterval
+	}
+	previous := w.Baseline
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if voices, ok := w.poll(ctx); ok {
+			if previous != nil {
+				if changes := model.DiffVoices(previous, voices); len(changes) > 0 && w.OnChange != nil {
+					w.OnChange(changes)
+				}
+			}
+			previous = voices
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-w.Client.Done():
+			return nil
+		}
+	}
+}
+
+func (w *VoiceWatcher) poll(ctx context.Context) ([]model.VoiceListResponse, bool) {
+	az := w.Client
+	voices, err := az.VoiceListRequest(ctx)
+	if err != nil {
+		if w.OnError != nil {
+			w.OnError(err)
+		} else {
+			az.Logger().WarnContext(ctx, "voice list poll failed", slog.Any("error", err))
+		}
+		return nil, false
+	}
+	select {
+	case <-az.Done():
+		// Close dropped the catalog, don't bring it back
+		return nil, false
+	default:
+	}
+
+	az.voicesMu.Lock()
+	az.voices = *voices
+	az.voicesFetched = time.Now()
+	az.voicesErr = nil
+	az.voicesMu.Unlock()
+	return *voices, true
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVoiceWatcher(t *testing.T) {
+	lists := []string{
+		`[{"ShortName":"a","VoiceType":"Neural","Status":"Preview"}]`,
+		`[]`, // fails below
+		`[{"ShortName":"a","VoiceType":"Neural","Status":"Preview"}]`,
+		`[{"ShortName":"a","VoiceType":"Neural","Status":"GA"},{"ShortName":"b","VoiceType":"Neural"}]`,
+	}
+	var polls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(polls.Add(1)) - 1
+		if n == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(lists[min(n, len(lists)-1)]))
+	}))
+	defer srv.Close()
+
+	az := &AzureTTSClient{SubscriptionKey: "key", HTTPClient: srv.Client(), VoiceServiceListURL: srv.URL}
+	changes := make(chan []model.VoiceChange, 10)
+	var failures atomic.Int32
+	w := &VoiceWatcher{
+		Client:   az,
+		Interval: time.Millisecond,
+		OnChange: func(c []model.VoiceChange) { changes <- c },
+		OnError:  func(error) { failures.Add(1) },
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+
+	var got []model.VoiceChange
+	select {
+	case got = <-changes:
+	case <-time.After(time.Second):
+		t.Fatal("no change reported")
+	}
+	cancel()
+	assert.True(t, errors.Is(<-done, context.Canceled))
+
+	require.Len(t, got, 2)
+	assert.Equal(t, model.VoiceStatusChanged, got[0].Kind)
+	assert.Equal(t, "GA", got[0].NewStatus)
+	assert.Equal(t, model.VoiceAdded, got[1].Kind)
+	assert.Equal(t, "b", got[1].ShortName)
+	assert.GreaterOrEqual(t, failures.Load(), int32(1))
+
+	voices, err := az.voiceCatalog(context.Background())
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, len(voices), 1, "polls refresh the catalog")
+}
+
+func TestVoiceWatcherStopsOnClose(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	az := &AzureTTSClient{SubscriptionKey: "key", HTTPClient: srv.Client(), VoiceServiceListURL: srv.URL}
+	done := make(chan error)
+	go func() { done <- az.WatchVoices(context.Background(), time.Hour, nil) }()
+	assert.NoError(t, az.Close(context.Background()))
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("watcher did not stop")
+	}
+}
@@ -0,0 +1,148 @@
+// Package auth provides Azure AD (Entra ID) credentials for the client as an alternative to
+// subscription keys. Every credential is an api.TokenSource:
+//
+//	cred := auth.NewClientSecretCredential(tenantID, clientID, secret)
+//	client, _ := azuretts.NewClient("", region,
+//		API.WithTokenSource(auth.AAD(resourceID, cred)))
+//
+// Tokens are cached and fetched again shortly before they expire. The endpoints of every credential
+// can be overridden, so they can be tested against a local fake token server.
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	API "github.com/barkingdog-ai/azure-tts/api"
+)
+
+const (
+	// Scope is the OAuth scope of tokens accepted by the speech service.
+	Scope = "https://cognitiveservices.azure.com/.default"
+	// Resource is the resource of managed identity tokens accepted by the speech service.
+	Resource = "https://cognitiveservices.azure.com/"
+	// DefaultAuthority is the Azure public cloud login endpoint.
+	DefaultAuthority = "https://login.microsoftonline.com"
+)
+
+// expiryMargin is how long before its expiry a cached token is replaced.
+const expiryMargin = 5 * time.Minute
+
+// defaultLifetime is the lifetime assumed for a token whose response gives no expiry. It is the
+// lifetime of the speech service's own tokens, shorter than that of any Azure AD token.
+const defaultLifetime = 10 * time.Minute
+
+// Token is an access token and the time it expires.
+type Token struct {
+	AccessToken string
+	ExpiresOn   time.Time
+}
+
+// Error is returned when a token endpoint rejects a request.
+type Error struct {
+	StatusCode  int
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("auth: token request failed with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("auth: token request failed with status %d: %s: %s", e.StatusCode, e.Code, e.Description)
+}
+
+// aadSource formats the tokens of a credential for the regional speech endpoints.
+type aadSource struct {
+	resourceID string
+	cred       API.TokenSource
+}
+
+// AAD returns a token source authorizing requests to the regional speech endpoints with the tokens
+// of cred, which must be given in the form aad#<resourceId>#<token>. resourceID is the Azure resource
+// ID of the speech resource. Endpoints on a custom domain accept the tokens of cred as they are.
+func AAD(resourceID string, cred API.TokenSource) API.TokenSource {
+	return &aadSource{resourceID: resourceID, cred: cred}
+}
+
+func (s *aadSource) Token(ctx context.Context) (string, error) {
+	token, err := s.cred.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+	return "aad#" + s.resourceID + "#" + token, nil
+}
+
+// cache holds the last token of a credential and fetches a new one when it is about to expire.
+// Concurrent callers wait for a single fetch.
+type cache struct {
+	mu    sync.Mutex
+	token Token
+}
+
+func (c *cache) get(ctx context.Context, fetch func(ctx context.Context) (Token, error)) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token.AccessToken != "" && time.Now().Add(expiryMargin).Before(c.token.ExpiresOn) {
+		return c.token.AccessToken, nil
+	}
+	token, err := fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	if token.ExpiresOn.IsZero() {
+		token.ExpiresOn = time.Now().Add(defaultLifetime)
+	}
+	c.token = token
+	return token.AccessToken, nil
+}
+
+// tokenResponse is the response of the Azure AD and managed identity token endpoints. Managed
+// identity endpoints send the numbers as strings.
+type tokenResponse struct {
+	AccessToken string      `json:"access_token"`
+	ExpiresIn   json.Number `json:"expires_in"`
+	ExpiresOn   json.Number `json:"expires_on"`
+}
+
+// do sends req with client and decodes the token in its response.
+func do(client *http.Client, req *http.Request) (Token, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Token{}, fmt.Errorf("auth: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Token{}, fmt.Errorf("auth: reading token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		e := &Error{StatusCode: resp.StatusCode}
+		_ = json.Unmarshal(data, e)
+		return Token{}, e
+	}
+
+	var body tokenResponse
+	if err := json.Unmarshal(data, &body); err != nil {
+		return Token{}, fmt.Errorf("auth: parsing token response: %w", err)
+	}
+	if body.AccessToken == "" {
+		return Token{}, fmt.Errorf("auth: token response without access_token")
+	}
+	token := Token{AccessToken: body.AccessToken}
+	if on, err := strconv.ParseInt(body.ExpiresOn.String(), 10, 64); err == nil {
+		token.ExpiresOn = time.Unix(on, 0)
+	} else if in, err := strconv.ParseInt(body.ExpiresIn.String(), 10, 64); err == nil {
+		token.ExpiresOn = time.Now().Add(time.Duration(in) * time.Second)
+	}
+	return token, nil
+}
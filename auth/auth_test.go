@@ -0,0 +1,200 @@
+package auth_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/barkingdog-ai/azure-tts/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTokenServer issues numbered tokens valid for expiresIn seconds, or without an expiry when
+// expiresIn is negative, and records the last request.
+type fakeTokenServer struct {
+	*httptest.Server
+	calls     int
+	expiresIn int
+	last      *http.Request
+	form      map[string]string
+}
+
+func newFakeTokenServer(t *testing.T, expiresIn int) *fakeTokenServer {
+	f := &fakeTokenServer{expiresIn: expiresIn}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.calls++
+		f.last = r
+		_ = r.ParseForm()
+		f.form = map[string]string{}
+		for k := range r.PostForm {
+			f.form[k] = r.PostForm.Get(k)
+		}
+		if f.form["client_secret"] == "wrong" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid_client","error_description":"bad secret"}`))
+			return
+		}
+		if f.expiresIn < 0 {
+			_, _ = fmt.Fprintf(w, `{"access_token":"token-%d"}`, f.calls)
+			return
+		}
+		// managed identity endpoints send expires_in as a string
+		_, _ = fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":"%d"}`, f.calls, f.expiresIn)
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func TestClientSecretCredential(t *testing.T) {
+	srv := newFakeTokenServer(t, 3600)
+	cred := auth.NewClientSecretCredential("tenant", "app", "secret")
+	cred.Authority = srv.URL
+
+	for i := 0; i < 2; i++ {
+		token, err := cred.Token(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "token-1", token)
+	}
+	assert.Equal(t, 1, srv.calls, "the token is cached")
+	assert.Equal(t, "/tenant/oauth2/v2.0/token", srv.last.URL.Path)
+	assert.Equal(t, map[string]string{
+		"grant_type":    "client_credentials",
+		"client_id":     "app",
+		"client_secret": "secret",
+		"scope":         auth.Scope,
+	}, srv.form)
+
+	cred = auth.NewClientSecretCredential("tenant", "app", "wrong")
+	cred.Authority = srv.URL
+	_, err := cred.Token(context.Background())
+	var authErr *auth.Error
+	require.ErrorAs(t, err, &authErr)
+	assert.Equal(t, http.StatusUnauthorized, authErr.StatusCode)
+	assert.Equal(t, "invalid_client", authErr.Code)
+}
+
+func TestTokenRefresh(t *testing.T) {
+	// tokens expiring within the refresh margin are fetched again
+	srv := newFakeTokenServer(t, 60)
+	cred := auth.NewClientSecretCredential("tenant", "app", "secret")
+	cred.Authority = srv.URL
+
+	first, err := cred.Token(context.Background())
+	assert.NoError(t, err)
+	second, err := cred.Token(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "token-1", first)
+	assert.Equal(t, "token-2", second)
+
+	// tokens without an expiry are kept for a conservative default lifetime
+	srv = newFakeTokenServer(t, -1)
+	cred = auth.NewClientSecretCredential("tenant", "app", "secret")
+	cred.Authority = srv.URL
+	for i := 0; i < 2; i++ {
+		token, err := cred.Token(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "token-1", token)
+	}
+	assert.Equal(t, 1, srv.calls)
+}
+
+func TestClientCertificateCredential(t *testing.T) {
+	srv := newFakeTokenServer(t, 3600)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "app"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	cred := auth.NewClientCertificateCredential("tenant", "app", cert, key)
+	cred.Authority = srv.URL
+	token, err := cred.Token(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+	assert.Equal(t, "urn:ietf:params:oauth:client-assertion-type:jwt-bearer", srv.form["client_assertion_type"])
+
+	parts := strings.Split(srv.form["client_assertion"], ".")
+	require.Len(t, parts, 3)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	assert.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature))
+
+	claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(claims, &got))
+	assert.Equal(t, srv.URL+"/tenant/oauth2/v2.0/token", got["aud"])
+	assert.Equal(t, "app", got["iss"])
+	assert.Equal(t, "app", got["sub"])
+}
+
+func TestManagedIdentityCredential(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		clientID   string
+		wantHeader string
+		wantValue  string
+		wantQuery  string
+	}{
+		{
+			name:       "instance metadata",
+			wantHeader: "Metadata",
+			wantValue:  "true",
+			wantQuery:  "api-version=2018-02-01&resource=https%3A%2F%2Fcognitiveservices.azure.com%2F",
+		},
+		{
+			name:       "app service user-assigned",
+			header:     "secret",
+			clientID:   "identity",
+			wantHeader: "X-IDENTITY-HEADER",
+			wantValue:  "secret",
+			wantQuery:  "api-version=2019-08-01&client_id=identity&resource=https%3A%2F%2Fcognitiveservices.azure.com%2F",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newFakeTokenServer(t, 3600)
+			cred := &auth.ManagedIdentityCredential{ClientID: tt.clientID, Endpoint: srv.URL, Header: tt.header}
+			token, err := cred.Token(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, "token-1", token)
+			assert.Equal(t, http.MethodGet, srv.last.Method)
+			assert.Equal(t, tt.wantValue, srv.last.Header.Get(tt.wantHeader))
+			assert.Equal(t, tt.wantQuery, srv.last.URL.RawQuery)
+		})
+	}
+}
+
+func TestAAD(t *testing.T) {
+	srv := newFakeTokenServer(t, 3600)
+	cred := auth.NewClientSecretCredential("tenant", "app", "secret")
+	cred.Authority = srv.URL
+
+	token, err := auth.AAD("/subscriptions/s/resourceGroups/g/providers/Microsoft.CognitiveServices/accounts/a", cred).
+		Token(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "aad#/subscriptions/s/resourceGroups/g/providers/Microsoft.CognitiveServices/accounts/a#token-1", token)
+}
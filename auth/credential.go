@@ -0,0 +1,202 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1" //nolint:gosec // x5t is defined as the SHA-1 thumbprint of the certificate
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// ClientSecretCredential authenticates an application registration with its client secret, using
+// the OAuth client credentials flow.
+type ClientSecretCredential struct {
+	TenantID     string
+	ClientID     string
+	ClientSecret string
+	// Authority is the login endpoint, DefaultAuthority when empty.
+	Authority string
+	// HTTPClient sends the token requests, http.DefaultClient when nil.
+	HTTPClient *http.Client
+
+	cache cache
+}
+
+// NewClientSecretCredential returns a credential for the application clientID in tenantID.
+func NewClientSecretCredential(tenantID, clientID, secret string) *ClientSecretCredential {
+	return &ClientSecretCredential{TenantID: tenantID, ClientID: clientID, ClientSecret: secret}
+}
+
+func (c *ClientSecretCredential) Token(ctx context.Context) (string, error) {
+	return c.cache.get(ctx, func(ctx context.Context) (Token, error) {
+		form := url.Values{
+			"grant_type":    {"client_credentials"},
+			"client_id":     {c.ClientID},
+			"client_secret": {c.ClientSecret},
+			"scope":         {Scope},
+		}
+		return postForm(ctx, c.HTTPClient, tokenEndpoint(c.Authority, c.TenantID), form)
+	})
+}
+
+// ClientCertificateCredential authenticates an application registration with a certificate, using
+// the OAuth client credentials flow with a signed client assertion.
+type ClientCertificateCredential struct {
+	TenantID    string
+	ClientID    string
+	Certificate *x509.Certificate
+	Key         *rsa.PrivateKey
+	// Authority is the login endpoint, DefaultAuthority when empty.
+	Authority string
+	// HTTPClient sends the token requests, http.DefaultClient when nil.
+	HTTPClient *http.Client
+
+	cache cache
+}
+
+// NewClientCertificateCredential returns a credential for the application clientID in tenantID
+// signing its assertions with key, the private key of cert.
+func NewClientCertificateCredential(tenantID, clientID string, cert *x509.Certificate,
+	key *rsa.PrivateKey,
+) *ClientCertificateCredential {
+	return &ClientCertificateCredential{TenantID: tenantID, ClientID: clientID, Certificate: cert, Key: key}
+}
+
+func (c *ClientCertificateCredential) Token(ctx context.Context) (string, error) {
+	return c.cache.get(ctx, func(ctx context.Context) (Token, error) {
+		endpoint := tokenEndpoint(c.Authority, c.TenantID)
+		assertion, err := c.assertion(endpoint, time.Now())
+		if err != nil {
+			return Token{}, err
+		}
+		form := url.Values{
+			"grant_type":            {"client_credentials"},
+			"client_id":             {c.ClientID},
+			"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+			"client_assertion":      {assertion},
+			"scope":                 {Scope},
+		}
+		return postForm(ctx, c.HTTPClient, endpoint, form)
+	})
+}
+
+// assertion returns the RS256 signed JWT proving possession of the certificate to audience.
+func (c *ClientCertificateCredential) assertion(audience string, now time.Time) (string, error) {
+	if c.Certificate == nil || c.Key == nil {
+		return "", fmt.Errorf("auth: certificate credential without certificate or key")
+	}
+	thumbprint := sha1.Sum(c.Certificate.Raw) //nolint:gosec // see import
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+		"x5t": base64.RawURLEncoding.EncodeToString(thumbprint[:]),
+	})
+	if err != nil {
+		return "", err
+	}
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"aud": audience,
+		"iss": c.ClientID,
+		"sub": c.ClientID,
+		"jti": hex.EncodeToString(jti),
+		"nbf": now.Unix(),
+		"exp": now.Add(10 * time.Minute).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, c.Key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("auth: signing client assertion: %w", err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// ManagedIdentityCredential authenticates with the managed identity of the Azure host. On App
+// Service and Functions the identity endpoint is taken from the IDENTITY_ENDPOINT and
+// IDENTITY_HEADER environment variables, elsewhere the instance metadata service is used.
+type ManagedIdentityCredential struct {
+	// ClientID selects a user-assigned identity, the system-assigned identity is used when empty.
+	ClientID string
+	// Endpoint overrides the identity endpoint.
+	Endpoint string
+	// Header is the secret sent as X-IDENTITY-HEADER to App Service identity endpoints.
+	Header string
+	// HTTPClient sends the token requests, http.DefaultClient when nil.
+	HTTPClient *http.Client
+
+	cache cache
+}
+
+// imdsEndpoint is the token endpoint of the Azure instance metadata service.
+const imdsEndpoint = "http://169.254.169.254/metadata/identity/oauth2/token"
+
+// NewManagedIdentityCredential returns a credential for the identity clientID, or for the
+// system-assigned identity when clientID is empty.
+func NewManagedIdentityCredential(clientID string) *ManagedIdentityCredential {
+	return &ManagedIdentityCredential{
+		ClientID: clientID,
+		Endpoint: os.Getenv("IDENTITY_ENDPOINT"),
+		Header:   os.Getenv("IDENTITY_HEADER"),
+	}
+}
+
+func (c *ManagedIdentityCredential) Token(ctx context.Context) (string, error) {
+	return c.cache.get(ctx, func(ctx context.Context) (Token, error) {
+		endpoint, apiVersion := c.Endpoint, "2018-02-01"
+		if endpoint == "" {
+			endpoint = imdsEndpoint
+		}
+		if c.Header != "" {
+			apiVersion = "2019-08-01"
+		}
+		query := url.Values{"api-version": {apiVersion}, "resource": {Resource}}
+		if c.ClientID != "" {
+			query.Set("client_id", c.ClientID)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+query.Encode(), nil)
+		if err != nil {
+			return Token{}, err
+		}
+		if c.Header != "" {
+			req.Header.Set("X-IDENTITY-HEADER", c.Header)
+		} else {
+			req.Header.Set("Metadata", "true")
+		}
+		return do(c.HTTPClient, req)
+	})
+}
+
+func tokenEndpoint(authority, tenantID string) string {
+	if authority == "" {
+		authority = DefaultAuthority
+	}
+	return strings.TrimSuffix(authority, "/") + "/" + url.PathEscape(tenantID) + "/oauth2/v2.0/token"
+}
+
+func postForm(ctx context.Context, client *http.Client, endpoint string, form url.Values) (Token, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Token{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return do(client, req)
+}
@@ -31,9 +31,27 @@ type Interface interface {
 	API.SpeechInterface
 	API.VoiceInterface
 	API.TokenInterface
+	API.DialogueInterface
+	// Close stops the background token refresher, waits for in-flight calls and flushes caches
+	// and metrics. Later calls fail with model.ErrClientClosed.
+	Close(ctx context.Context) error
 }
 
+// NewClient creates a client for region, fetching its first token with a 30 second timeout.
+// See NewClientWithContext.
 func NewClient(subscriptionKey string, region model.Region, options ...API.ClientOption) (*API.AzureTTSClient, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), synthesizeActionTimeout)
+	defer cancel()
+	return NewClientWithContext(ctx, subscriptionKey, region, options...)
+}
+
+// NewClientWithContext creates a client for region. Options are applied first; then, unless
+// API.WithLazyAuth, API.WithTokenSource or API.WithStaticToken was given, the first token is
+// fetched within ctx and construction fails if Azure can't be reached. Tokens for the subscription
+// key are refreshed in the background until the client is closed.
+func NewClientWithContext(ctx context.Context, subscriptionKey string, region model.Region,
+	options ...API.ClientOption,
+) (*API.AzureTTSClient, error) {
 	httpClient := &http.Client{
 		Timeout: defaultTimeoutSeconds * time.Second,
 	}
@@ -41,35 +59,38 @@ func NewClient(subscriptionKey string, region model.Region, options ...API.Clien
 	az := &API.AzureTTSClient{
 		SubscriptionKey: subscriptionKey,
 		HTTPClient:      httpClient,
+		Region:          region,
 	}
 	az.TextToSpeechURL = fmt.Sprintf(textToSpeechAPI, region)
 	az.SpeechToTextURL = fmt.Sprintf(speechToTextAPI, region)
 	az.TokenRefreshURL = fmt.Sprintf(refreshAPI, region)
 	az.VoiceServiceListURL = fmt.Sprintf(voiceListAPI, region)
 
-	// api requires that the token is refreshed every 10 mintutes.
-	// We will do this task in the background every ~9 minutes.
-	ctx, cancel := context.WithTimeout(context.Background(), synthesizeActionTimeout)
-	defer cancel()
-	err := az.RefreshToken(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch initial token, %v", err)
-	}
-
-	az.TokenRefreshDoneCh = startRefresher(ctx, az)
-
 	for _, o := range options {
 		if err := o(az); err != nil {
 			return nil, err
 		}
 	}
+
+	if az.NeedsInitialToken() {
+		if err := az.RefreshToken(ctx); err != nil {
+			return nil, fmt.Errorf("failed to fetch initial token, %w", err)
+		}
+	}
+
+	// api requires that the token is refreshed every 10 mintutes.
+	// We will do this task in the background every ~9 minutes.
+	az.TokenRefreshDoneCh = make(chan bool, 1)
+	if az.RefreshesToken() {
+		startRefresher(az)
+	}
 	return az, nil
 }
 
-// startRefresher updates the authentication token on at a 9 minute interval. A channel is returned
-// if the caller wishes to cancel the channel.
-func startRefresher(ctx context.Context, az *API.AzureTTSClient) chan bool {
-	done := make(chan bool, 1)
+// startRefresher updates the authentication token on at a 9 minute interval. It stops when the client
+// is closed, or when az.TokenRefreshDoneCh is closed by callers not using Close.
+func startRefresher(az *API.AzureTTSClient) {
+	done := az.TokenRefreshDoneCh
 	go func() {
 		const refreshInterval = time.Minute * 9
 		ticker := time.NewTicker(refreshInterval)
@@ -77,14 +98,15 @@ func startRefresher(ctx context.Context, az *API.AzureTTSClient) chan bool {
 		for {
 			select {
 			case <-ticker.C:
-				err := az.RefreshToken(ctx)
-				if err != nil {
-					_ = fmt.Sprintf("failed to refresh token, %v", err)
-				}
+				ctx, cancel := context.WithTimeout(context.Background(), synthesizeActionTimeout)
+				// failures are logged by RefreshToken, an expired token is fetched again on next use
+				_ = az.RefreshToken(ctx)
+				cancel()
 			case <-done:
 				return
+			case <-az.Done():
+				return
 			}
 		}
 	}()
-	return done
 }
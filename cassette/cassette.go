@@ -0,0 +1,240 @@
+// Package cassette records the HTTP exchanges of the client with Azure and replays them, so tests
+// can run deterministically without network access or a subscription key.
+//
+// Record once against the real service:
+//
+//	rec := cassette.New("testdata/cassettes", cassette.ModeRecord, nil)
+//	client, _ := azuretts.NewClient(key, region, API.WithHTTPClient(rec.Client()))
+//
+// and replay in CI with cassette.ModeReplay. Every interaction is stored as a JSON file named after
+// the method, URL and normalized body of its request. Subscription keys, bearer tokens and issued
+// tokens are scrubbed before anything is written. In replay mode a request without a recording fails
+// with an *UnmatchedError instead of reaching the network.
+package cassette
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Mode selects whether a Recorder talks to the service or to its cassette.
+type Mode int
+
+const (
+	// ModeReplay answers every request from the cassette.
+	ModeReplay Mode = iota
+	// ModeRecord sends every request to the service and records the exchange.
+	ModeRecord
+)
+
+// scrubbed replaces secrets in recorded interactions.
+const scrubbed = "[SCRUBBED]"
+
+// secretHeaders are removed from recorded requests and responses.
+var secretHeaders = []string{"Ocp-Apim-Subscription-Key", "Authorization", "Set-Cookie"}
+
+// Interaction is one recorded request and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is the recorded part of a request. Body is kept for text bodies only, binary bodies such
+// as uploaded audio are identified by their hash.
+type Request struct {
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	BodySHA256 string      `json:"body_sha256"`
+}
+
+// Response is a recorded response.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       []byte      `json:"body,omitempty"`
+}
+
+// UnmatchedError is returned in replay mode for a request that was never recorded.
+type UnmatchedError struct {
+	Method string
+	URL    string
+	File   string
+}
+
+func (e *UnmatchedError) Error() string {
+	return fmt.Sprintf("cassette: no recorded interaction for %s %s (expected %s), record it with ModeRecord",
+		e.Method, e.URL, e.File)
+}
+
+// Recorder is an http.RoundTripper recording to or replaying from a cassette directory.
+type Recorder struct {
+	dir  string
+	mode Mode
+	next http.RoundTripper
+	mu   sync.Mutex
+}
+
+// New returns a Recorder using the cassette in dir. In record mode requests are sent with next,
+// http.DefaultTransport when nil.
+func New(dir string, mode Mode, next http.RoundTripper) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Recorder{dir: dir, mode: mode, next: next}
+}
+
+// Client returns an http.Client sending its requests through r, for use with api.WithHTTPClient.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, fmt.Errorf("cassette: reading request body: %w", err)
+		}
+		req.Body.Close()
+	}
+	file := filepath.Join(r.dir, fileName(req.Method, req.URL.String(), body))
+
+	if r.mode == ModeReplay {
+		return r.replay(req, file)
+	}
+
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	resp, err := r.next.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("cassette: reading response body: %w", err)
+	}
+	if err := r.record(file, req, body, resp, respBody); err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request, file string) (*http.Response, error) {
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, &UnmatchedError{Method: req.Method, URL: req.URL.String(), File: file}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cassette: %w", err)
+	}
+	var in Interaction
+	if err := json.Unmarshal(data, &in); err != nil {
+		return nil, fmt.Errorf("cassette: parsing %s: %w", file, err)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+		StatusCode:    in.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        in.Response.Header,
+		Body:          io.NopCloser(bytes.NewReader(in.Response.Body)),
+		ContentLength: int64(len(in.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (r *Recorder) record(file string, req *http.Request, body []byte, resp *http.Response, respBody []byte) error {
+	in := Interaction{
+		Request: Request{
+			Method:     req.Method,
+			URL:        req.URL.String(),
+			Header:     scrubHeader(req.Header),
+			BodySHA256: hash(body),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     scrubHeader(resp.Header),
+			Body:       respBody,
+		},
+	}
+	if utf8.Valid(body) {
+		in.Request.Body = string(body)
+	}
+	// the token endpoint answers with a bearer token
+	if strings.HasSuffix(req.URL.Path, "/issueToken") && resp.StatusCode == http.StatusOK {
+		in.Response.Body = []byte(scrubbed)
+	}
+
+	data, err := json.MarshalIndent(in, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cassette: %w", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return fmt.Errorf("cassette: %w", err)
+	}
+	if err := os.WriteFile(file, data, 0o644); err != nil {
+		return fmt.Errorf("cassette: %w", err)
+	}
+	return nil
+}
+
+func scrubHeader(h http.Header) http.Header {
+	out := h.Clone()
+	for _, name := range secretHeaders {
+		if out.Get(name) != "" {
+			out.Set(name, scrubbed)
+		}
+	}
+	return out
+}
+
+// fileName names the recording of a request after its method and a hash of its URL and normalized body.
+func fileName(method, url string, body []byte) string {
+	sum := sha256.Sum256([]byte(method + "\n" + url + "\n" + string(NormalizeSSML(body))))
+	return strings.ToLower(method) + "-" + hex.EncodeToString(sum[:8]) + ".json"
+}
+
+func hash(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+var (
+	reBetweenTags   = regexp.MustCompile(`>\s+<`)
+	reWhitespace    = regexp.MustCompile(`\s+`)
+	reSingleQuoted  = regexp.MustCompile(`='([^'"]*)'`)
+	reSSMLStartTags = regexp.MustCompile(`^\s*(<\?xml[^>]*\?>\s*)?<speak[\s>]`)
+)
+
+// NormalizeSSML returns body with the formatting differences of equivalent SSML documents removed:
+// white space between tags is dropped, other runs of white space are collapsed and attributes are
+// double quoted. Bodies that are not SSML are returned unchanged.
+func NormalizeSSML(body []byte) []byte {
+	if !reSSMLStartTags.Match(body) {
+		return body
+	}
+	s := strings.TrimSpace(string(body))
+	s = reBetweenTags.ReplaceAllString(s, "><")
+	s = reWhitespace.ReplaceAllString(s, " ")
+	s = reSingleQuoted.ReplaceAllString(s, `="$1"`)
+	return []byte(s)
+}
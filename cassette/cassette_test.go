@@ -0,0 +1,86 @@
+package cassette_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/barkingdog-ai/azure-tts/cassette"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if strings.HasSuffix(r.URL.Path, "/issueToken") {
+			_, _ = w.Write([]byte("real-token"))
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "audio/mpeg")
+		_, _ = w.Write(append([]byte("audio:"), body...))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	post := func(client *http.Client, path, body string) (string, error) {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+path, strings.NewReader(body))
+		req.Header.Set("Ocp-Apim-Subscription-Key", "secret-key")
+		req.Header.Set("Authorization", "Bearer secret-token")
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		return string(data), err
+	}
+
+	rec := cassette.New(dir, cassette.ModeRecord, nil).Client()
+	got, err := post(rec, "/tts", `<speak version='1.0'><voice name='a'>你好</voice></speak>`)
+	assert.NoError(t, err)
+	assert.Equal(t, `audio:<speak version='1.0'><voice name='a'>你好</voice></speak>`, got)
+	got, err = post(rec, "/sts/v1.0/issueToken", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "real-token", got, "the caller still sees the real token while recording")
+	assert.Equal(t, 2, calls)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	assert.Len(t, files, 2)
+	for _, f := range files {
+		data, _ := os.ReadFile(f)
+		assert.NotContains(t, string(data), "secret-key")
+		assert.NotContains(t, string(data), "secret-token")
+		assert.NotContains(t, string(data), "real-token")
+	}
+
+	replay := cassette.New(dir, cassette.ModeReplay, nil).Client()
+	got, err = post(replay, "/tts", "<speak version=\"1.0\">\n  <voice name=\"a\">你好</voice>\n</speak>")
+	assert.NoError(t, err)
+	assert.Equal(t, `audio:<speak version='1.0'><voice name='a'>你好</voice></speak>`, got)
+	assert.Equal(t, 2, calls, "replay must not reach the service")
+
+	_, err = post(replay, "/tts", `<speak version='1.0'><voice name='a'>再見</voice></speak>`)
+	var unmatched *cassette.UnmatchedError
+	assert.True(t, errors.As(err, &unmatched))
+	assert.Equal(t, http.MethodPost, unmatched.Method)
+}
+
+func TestNormalizeSSML(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "<speak version='1.0'>\n\t<voice name='a'>hi   there</voice>\n</speak>\n", want: `<speak version="1.0"><voice name="a">hi there</voice></speak>`},
+		{in: `{"text":  "not ssml"}`, want: `{"text":  "not ssml"}`},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, string(cassette.NormalizeSSML([]byte(tt.in))))
+	}
+}
@@ -0,0 +1,139 @@
+// Command azuretts-server runs the HTTP TTS gateway of package server in front of a single Azure
+// subscription, so internal services can synthesize speech without holding the Azure key.
+//
+// The Azure key and region are read from AZURE_API_KEY and AZURE_REGION, the keys accepted from
+// callers from the comma separated AZURE_TTS_SERVER_KEYS or --keys. The server refuses to start
+// without caller keys unless --insecure-no-auth is given. The OpenAI compatible
+// endpoints map OpenAI voices with --voice-alias, e.g. --voice-alias alloy=zh-TW-HsiaoYuNeural.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	azuretts "github.com/barkingdog-ai/azure-tts"
+	"github.com/barkingdog-ai/azure-tts/model"
+	"github.com/barkingdog-ai/azure-tts/server"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %+v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	region := flag.String("region", os.Getenv("AZURE_REGION"), "Azure region (default from AZURE_REGION, else eastasia)")
+	keys := flag.String("keys", os.Getenv("AZURE_TTS_SERVER_KEYS"), "comma separated API keys accepted from callers, required unless --insecure-no-auth")
+	noAuth := flag.Bool("insecure-no-auth", false, "accept requests from anyone who can reach the server, without an API key")
+	rate := flag.Float64("rate-limit", 5, "requests per second allowed per caller, 0 disables rate limiting")
+	burst := flag.Int("rate-burst", 10, "burst size of the per caller rate limit")
+	cacheEntries := flag.Int("cache", 256, "number of synthesized clips kept in memory, 0 disables the cache")
+	voice := flag.String("voice", "zh-TW-HsiaoChenNeural", "default voice")
+	format := flag.String("format", "audio-16khz-32kbitrate-mono-mp3", "default audio format")
+	profiles := flag.String("styles", "", "JSON or YAML file of extra style profiles")
+	aliases := flag.String("voice-alias", "", "comma separated OpenAI voice aliases, e.g. alloy=zh-TW-HsiaoYuNeural, added to the defaults")
+	flag.Parse()
+
+	key := os.Getenv("AZURE_API_KEY")
+	if key == "" {
+		return fmt.Errorf("AZURE_API_KEY is not set")
+	}
+	if *region == "" {
+		*region = "eastasia"
+	}
+	azRegion, err := model.ParseRegion(*region)
+	if err != nil {
+		return err
+	}
+	if _, err := model.StringToAudioOutput(*format); err != nil {
+		return err
+	}
+	if *profiles != "" {
+		if err := azuretts.LoadStyleProfiles(*profiles); err != nil {
+			return err
+		}
+	}
+
+	client, err := azuretts.NewClient(key, azRegion)
+	if err != nil {
+		return err
+	}
+
+	voiceAliases := make(map[string]string, len(server.DefaultVoiceAliases))
+	for alias, voice := range server.DefaultVoiceAliases {
+		voiceAliases[alias] = voice
+	}
+	for _, pair := range strings.Split(*aliases, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		alias, voice, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("invalid voice alias %q, expected name=voice", pair)
+		}
+		voiceAliases[strings.ToLower(alias)] = voice
+	}
+
+	var callerKeys []string
+	for _, k := range strings.Split(*keys, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			callerKeys = append(callerKeys, k)
+		}
+	}
+	if len(callerKeys) == 0 {
+		if !*noAuth {
+			return fmt.Errorf("no caller keys configured, set --keys or AZURE_TTS_SERVER_KEYS, or pass --insecure-no-auth")
+		}
+		log.Print("WARNING: authentication is disabled, anyone who can reach the server can use the Azure subscription")
+	}
+
+	handler, err := server.New(client, server.Config{
+		APIKeys:              callerKeys,
+		AllowUnauthenticated: *noAuth,
+		RateLimit:            *rate,
+		RateBurst:            *burst,
+		CacheEntries:         *cacheEntries,
+		DefaultVoice:         *voice,
+		DefaultFormat:        *format,
+		VoiceAliases:         voiceAliases,
+	})
+	if err != nil {
+		_ = client.Close(context.Background())
+		return err
+	}
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	stopped := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+		stopped <- client.Close(shutdownCtx)
+	}()
+
+	log.Printf("listening on %s", *addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		_ = client.Close(context.Background())
+		return err
+	}
+	return <-stopped
+}
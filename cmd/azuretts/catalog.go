@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	azuretts "github.com/barkingdog-ai/azure-tts"
+	"github.com/barkingdog-ai/azure-tts/model"
+)
+
+func runVoices(args []string) error {
+	fs := flag.NewFlagSet("voices", flag.ContinueOnError)
+	var client clientFlags
+	client.register(fs)
+	locale := fs.String("locale", "", "only voices of this locale, or locale prefix such as zh")
+	gender := fs.String("gender", "", "only voices of this gender")
+	style := fs.String("style", "", "only voices supporting this speaking style")
+	asJSON := fs.Bool("json", false, "print JSON instead of a table")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	az, err := client.newClient()
+	if err != nil {
+		return err
+	}
+	defer az.Close(context.Background())
+
+	voices, err := az.VoiceList(context.Background())
+	if err != nil {
+		return fmt.Errorf("unable to list voices, received: %w", err)
+	}
+
+	filtered := make([]model.VoiceListResponse, 0, len(*voices))
+	for i := range *voices {
+		v := &(*voices)[i]
+		if *locale != "" && !strings.HasPrefix(strings.ToLower(v.Locale), strings.ToLower(*locale)) {
+			continue
+		}
+		if *gender != "" && !strings.EqualFold(v.Gender, *gender) {
+			continue
+		}
+		if *style != "" && !v.SupportsStyle(*style) {
+			continue
+		}
+		filtered = append(filtered, *v)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(filtered)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tLOCALE\tGENDER\tTYPE\tSTYLES")
+	for i := range filtered {
+		v := &filtered[i]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", v.ShortName, v.Locale, v.Gender, v.VoiceType, strings.Join(v.StyleList, ","))
+	}
+	return w.Flush()
+}
+
+func runVoicesDiff(args []string) error {
+	fs := flag.NewFlagSet("voices-diff", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return fmt.Errorf("voices-diff takes an old and a new voice list file")
+	}
+	older, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	newer, err := os.ReadFile(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	changes, err := model.DiffVoiceSnapshots(older, newer)
+	if err != nil {
+		return err
+	}
+	for _, c := range changes {
+		fmt.Println(c)
+	}
+	return nil
+}
+
+func runTranscribe(args []string) error {
+	fs := flag.NewFlagSet("transcribe", flag.ContinueOnError)
+	var client clientFlags
+	client.register(fs)
+	language := fs.String("language", "zh-TW", "language of the speech")
+	asJSON := fs.Bool("json", false, "print the full recognition result as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("transcribe takes exactly one audio file")
+	}
+
+	az, err := client.newClient()
+	if err != nil {
+		return err
+	}
+	defer az.Close(context.Background())
+
+	resp, err := az.SpeechToText(context.Background(), model.SpeechToTextReq{
+		FilePath: fs.Arg(0),
+		Language: *language,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to transcribe, received: %w", err)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(resp)
+	}
+	fmt.Println(resp.DisplayText)
+	return nil
+}
+
+func runStyles(args []string) error {
+	fs := flag.NewFlagSet("styles", flag.ContinueOnError)
+	profiles := fs.String("load", "", "JSON or YAML file of extra style profiles to register first")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *profiles != "" {
+		if err := azuretts.LoadStyleProfiles(*profiles); err != nil {
+			return err
+		}
+	}
+
+	styles := azuretts.ListAvailableStyles()
+	names := make([]string, 0, len(styles))
+	for name := range styles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSTYLE\tDEGREE\tROLE\tFALLBACKS\tDESCRIPTION")
+	for _, name := range names {
+		profile, err := azuretts.GetStyleProfile(name)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", name, profile.Style, profile.StyleDegree, profile.Role,
+			strings.Join(profile.Fallbacks, ","), profile.Description)
+	}
+	return w.Flush()
+}
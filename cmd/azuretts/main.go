@@ -0,0 +1,163 @@
+// Command azuretts synthesizes and transcribes speech with Azure Cognitive Services from the command line.
+//
+//	azuretts speak [flags] [text]     text, --file or stdin to an audio file or stdout
+//	azuretts voices [flags]           list the voice catalog as a table or JSON
+//	azuretts transcribe [flags] FILE  recognize speech in an audio file
+//	azuretts styles                   list the predefined style profiles
+//	azuretts ssml [flags] [text]      print the SSML speak would send, without calling Azure
+//	azuretts config [flags]           print the effective configuration, with the key redacted
+//
+// The client is configured by the YAML, JSON or TOML file given by --config or found at
+// $XDG_CONFIG_HOME/azuretts/config.{json,yaml,yml,toml}, overridden by the AZURE_TTS_* environment
+// variables (see azuretts.Config). AZURE_API_KEY, AZURE_KEY and AZURE_REGION are read as well.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	azuretts "github.com/barkingdog-ai/azure-tts"
+	API "github.com/barkingdog-ai/azure-tts/api"
+)
+
+const usage = `Usage: azuretts <command> [flags]
+
+Commands:
+  speak       synthesize text to an audio file or stdout
+  voices      list available voices
+  voices-diff compare two voice lists saved with "voices -json"
+  transcribe  transcribe an audio file
+  styles      list predefined style profiles
+  ssml        print the generated SSML without calling Azure
+  config      print the effective configuration
+
+Run "azuretts <command> -h" for the flags of a command.
+`
+
+const defaultRegion = "eastasia"
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	commands := map[string]func(args []string) error{
+		"speak":       runSpeak,
+		"voices":      runVoices,
+		"voices-diff": runVoicesDiff,
+		"transcribe":  runTranscribe,
+		"styles":      runStyles,
+		"ssml":        runSSML,
+		"config":      runConfig,
+	}
+	run, ok := commands[os.Args[1]]
+	if !ok {
+		if os.Args[1] == "help" || os.Args[1] == "-h" || os.Args[1] == "--help" {
+			fmt.Print(usage)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	if err := run(os.Args[2:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "ERROR: %+v\n", err)
+		os.Exit(1)
+	}
+}
+
+// clientFlags registers the flags needed to reach Azure on fs.
+type clientFlags struct {
+	configPath string
+	region     string
+}
+
+func (c *clientFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&c.configPath, "config", "", "path of a YAML, JSON or TOML config file")
+	fs.StringVar(&c.region, "region", "", "Azure region, e.g. eastasia (default from AZURE_TTS_REGION, AZURE_REGION or config)")
+}
+
+// newClient builds a client from the effective configuration, see config.
+func (c *clientFlags) newClient() (*API.AzureTTSClient, error) {
+	cfg, err := c.config()
+	if err != nil {
+		return nil, err
+	}
+	return azuretts.NewClientFromConfig(context.Background(), cfg)
+}
+
+// config returns the configuration of the config file, the environment and the flags, in increasing
+// priority. It is not validated.
+func (c *clientFlags) config() (*azuretts.Config, error) {
+	cfg := azuretts.DefaultConfig()
+	cfg.Region = defaultRegion
+	path, err := configPath(c.configPath)
+	if err != nil {
+		return nil, err
+	}
+	if path != "" {
+		if err := cfg.LoadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	// AZURE_KEY and AZURE_REGION predate the AZURE_TTS_* variables, which take precedence
+	if key := os.Getenv("AZURE_KEY"); key != "" {
+		cfg.Key = key
+	}
+	if region := os.Getenv("AZURE_REGION"); region != "" {
+		cfg.Region = region
+	}
+	if err := cfg.LoadEnv(); err != nil {
+		return nil, err
+	}
+	if c.region != "" {
+		cfg.Region = c.region
+	}
+	return &cfg, nil
+}
+
+// configPath returns path, or else the first config file found at the default location, or "" when
+// there is none.
+func configPath(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", nil
+	}
+	for _, name := range []string{"config.json", "config.yaml", "config.yml", "config.toml"} {
+		candidate := filepath.Join(dir, "azuretts", name)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("reading config: %w", err)
+		}
+	}
+	return "", nil
+}
+
+// runConfig prints the effective configuration with the key redacted, and whether it is valid.
+func runConfig(args []string) error {
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	var client clientFlags
+	client.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := client.config()
+	if err != nil {
+		return err
+	}
+	fmt.Print(cfg.Dump())
+	return cfg.Validate()
+}
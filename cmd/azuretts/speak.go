@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	azuretts "github.com/barkingdog-ai/azure-tts"
+	API "github.com/barkingdog-ai/azure-tts/api"
+	"github.com/barkingdog-ai/azure-tts/model"
+)
+
+const (
+	defaultVoice  = "zh-TW-HsiaoChenNeural"
+	defaultFormat = "audio-16khz-32kbitrate-mono-mp3"
+)
+
+// speechFlags are the flags shared by speak and ssml.
+type speechFlags struct {
+	file   string
+	voice  string
+	locale string
+	gender string
+	style  string
+	format string
+	rate   string
+	pitch  string
+	markup bool
+}
+
+func (s *speechFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&s.file, "file", "", "read the text from this file instead of the arguments or stdin")
+	fs.StringVar(&s.voice, "voice", "", "voice short name (default from config, else "+defaultVoice+")")
+	fs.StringVar(&s.locale, "locale", "", "locale of the text (default taken from the voice name)")
+	fs.StringVar(&s.gender, "gender", "Female", "voice gender, Female or Male")
+	fs.StringVar(&s.style, "style", "", "predefined style profile, see \"azuretts styles\"")
+	fs.StringVar(&s.format, "format", "", "audio output format (default from config, else "+defaultFormat+")")
+	fs.StringVar(&s.rate, "rate", "", "speaking rate, e.g. 1.2, +10% or fast")
+	fs.StringVar(&s.pitch, "pitch", "", "pitch, e.g. +5%, -2st, 180Hz or high")
+	fs.BoolVar(&s.markup, "markup", false, "enable [[tag:args|text]] inline markup in the text")
+}
+
+// request builds the synthesis request for the text given as args, --file or stdin. The voice and
+// format not given as flags are the defaults of cfg.
+func (s *speechFlags) request(args []string, cfg *azuretts.Config) (*model.TextToSpeechRequest, error) {
+	text, err := s.text(args)
+	if err != nil {
+		return nil, err
+	}
+	voice := firstNonEmpty(s.voice, cfg.DefaultVoice, defaultVoice)
+
+	localeName := s.locale
+	if localeName == "" {
+		parts := strings.SplitN(voice, "-", 3)
+		if len(parts) < 3 {
+			return nil, fmt.Errorf("can't derive the locale from voice %q, use --locale", voice)
+		}
+		localeName = parts[0] + "-" + parts[1]
+	}
+	locale, err := model.ParseLocale(localeName)
+	if err != nil {
+		return nil, err
+	}
+	gender, err := model.GenderString(s.gender)
+	if err != nil {
+		return nil, err
+	}
+	output, err := model.StringToAudioOutput(firstNonEmpty(s.format, cfg.Format, defaultFormat))
+	if err != nil {
+		return nil, err
+	}
+
+	prosody := &model.Prosody{}
+	if prosody.Rate, err = model.ParseProsodyValue(s.rate); err != nil {
+		return nil, err
+	}
+	if prosody.Pitch, err = model.ParseProsodyValue(s.pitch); err != nil {
+		return nil, err
+	}
+
+	req := &model.TextToSpeechRequest{
+		SpeechText:   text,
+		Locale:       locale,
+		Gender:       gender,
+		VoiceName:    voice,
+		AudioOutput:  output,
+		Prosody:      prosody,
+		InlineMarkup: s.markup,
+	}
+	if s.style != "" {
+		if req.StyleProfile, err = azuretts.GetStyleProfile(s.style); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+func (s *speechFlags) text(args []string) (string, error) {
+	switch {
+	case s.file != "":
+		data, err := os.ReadFile(s.file)
+		if err != nil {
+			return "", fmt.Errorf("reading text file: %w", err)
+		}
+		return string(data), nil
+	case len(args) > 0:
+		return strings.Join(args, " "), nil
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("reading stdin: %w", err)
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		return "", fmt.Errorf("no text given, pass it as arguments, with --file or on stdin")
+	}
+	return string(data), nil
+}
+
+func runSpeak(args []string) error {
+	fs := flag.NewFlagSet("speak", flag.ContinueOnError)
+	var client clientFlags
+	var speech speechFlags
+	client.register(fs)
+	speech.register(fs)
+	out := fs.String("out", "-", "output audio file, - for stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := client.config()
+	if err != nil {
+		return err
+	}
+	if err := cfg.RegisterStyleProfiles(); err != nil {
+		return err
+	}
+	req, err := speech.request(fs.Args(), cfg)
+	if err != nil {
+		return err
+	}
+	az, err := azuretts.NewClientFromConfig(context.Background(), cfg)
+	if err != nil {
+		return err
+	}
+	defer az.Close(context.Background())
+
+	audio, err := az.TextToSpeech(context.Background(), req)
+	if err != nil {
+		return fmt.Errorf("unable to synthesize, received: %w", err)
+	}
+	if *out == "-" {
+		_, err = os.Stdout.Write(audio)
+		return err
+	}
+	const filePermission = 0o600
+	return os.WriteFile(*out, audio, filePermission)
+}
+
+func runSSML(args []string) error {
+	fs := flag.NewFlagSet("ssml", flag.ContinueOnError)
+	var client clientFlags
+	var speech speechFlags
+	client.register(fs)
+	speech.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := client.config()
+	if err != nil {
+		return err
+	}
+	if err := cfg.RegisterStyleProfiles(); err != nil {
+		return err
+	}
+	req, err := speech.request(fs.Args(), cfg)
+	if err != nil {
+		return err
+	}
+	ssml, err := API.BuildSSML(req)
+	if err != nil {
+		return err
+	}
+	fmt.Println(ssml)
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,418 @@
+package azuretts
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	API "github.com/barkingdog-ai/azure-tts/api"
+	"github.com/barkingdog-ai/azure-tts/model"
+	"gopkg.in/yaml.v3"
+)
+
+// envPrefix prefixes the environment variables read by Config.LoadEnv.
+const envPrefix = "AZURE_TTS_"
+
+// defaultRetryBackoff is the wait before the first retry when Retry.Backoff is not set.
+const defaultRetryBackoff = 500 * time.Millisecond
+
+// Config configures a client, e.g. loaded with LoadConfig from a YAML, JSON or TOML file:
+//
+//	key: ...
+//	region: eastasia
+//	default_voice: zh-TW-HsiaoChenNeural
+//	format: audio-24khz-48kbitrate-mono-mp3
+//	timeout: 30s
+//	retry:
+//	  max_attempts: 3
+//	  backoff: 500ms
+//	rate_limit:
+//	  requests_per_second: 20
+//	style_profiles: [styles.yaml]
+//
+// Every field can be set with an environment variable named after its path, e.g. AZURE_TTS_KEY,
+// AZURE_TTS_DEFAULT_VOICE or AZURE_TTS_RETRY_MAX_ATTEMPTS. Lists are comma separated.
+type Config struct {
+	// Key is the subscription key of the Speech resource.
+	Key string `json:"key" yaml:"key" toml:"key"`
+	// Region is the region of the Speech resource, e.g. eastasia.
+	Region string `json:"region" yaml:"region" toml:"region"`
+	// Endpoint replaces https://<region>.tts.speech.microsoft.com for synthesis and the voice list,
+	// e.g. to go through a proxy. Speech to text keeps using https://<region>.stt.speech.microsoft.com.
+	Endpoint string `json:"endpoint" yaml:"endpoint" toml:"endpoint"`
+	// DefaultVoice and Format are the voice and output format of the requests made by NewRequest.
+	DefaultVoice string `json:"default_voice" yaml:"default_voice" toml:"default_voice"`
+	Format       string `json:"format"        yaml:"format"        toml:"format"`
+	// Timeout limits every request to Azure, TokenTimeout the first token fetch while the client is built.
+	Timeout      Duration        `json:"timeout"       yaml:"timeout"       toml:"timeout"`
+	TokenTimeout Duration        `json:"token_timeout" yaml:"token_timeout" toml:"token_timeout"`
+	Retry        RetryConfig     `json:"retry"         yaml:"retry"         toml:"retry"`
+	Cache        CacheConfig     `json:"cache"         yaml:"cache"         toml:"cache"`
+	RateLimit    RateLimitConfig `json:"rate_limit"    yaml:"rate_limit"    toml:"rate_limit"`
+	// StyleProfiles are JSON or YAML files of style profiles, registered in the package-wide registry of
+	// GetStyleProfile by LoadConfig or RegisterStyleProfiles.
+	StyleProfiles []string `json:"style_profiles" yaml:"style_profiles" toml:"style_profiles"`
+	// Lexicons are the URLs of custom lexicons used by every synthesis, see API.WithLexicons.
+	Lexicons []string `json:"lexicons" yaml:"lexicons" toml:"lexicons"`
+}
+
+// RetryConfig configures the retries of failed requests, see API.WithRetry.
+type RetryConfig struct {
+	// MaxAttempts is the number of times a request is sent, at most. Zero or one disables retries.
+	MaxAttempts int      `json:"max_attempts" yaml:"max_attempts" toml:"max_attempts"`
+	Backoff     Duration `json:"backoff"      yaml:"backoff"      toml:"backoff"`
+}
+
+// CacheConfig configures caching.
+type CacheConfig struct {
+	// VoiceListTTL is how long the voice list used for style resolution and voice selection is reused.
+	VoiceListTTL Duration `json:"voice_list_ttl" yaml:"voice_list_ttl" toml:"voice_list_ttl"`
+}
+
+// RateLimitConfig limits the requests sent to Azure, see API.WithRateLimit. Zero disables the limit.
+type RateLimitConfig struct {
+	RequestsPerSecond float64 `json:"requests_per_second" yaml:"requests_per_second" toml:"requests_per_second"`
+	Burst             int     `json:"burst"               yaml:"burst"               toml:"burst"`
+}
+
+// Duration is a time.Duration written as a string such as "30s" or "1h30m" in configuration.
+type Duration time.Duration
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// DefaultConfig returns the configuration LoadConfig starts from.
+func DefaultConfig() Config {
+	return Config{
+		Format:       model.Audio16khz32kbitrateMonoMp3.String(),
+		Timeout:      Duration(defaultTimeoutSeconds * time.Second),
+		TokenTimeout: Duration(synthesizeActionTimeout),
+		Retry:        RetryConfig{MaxAttempts: 1, Backoff: Duration(defaultRetryBackoff)},
+		Cache:        CacheConfig{VoiceListTTL: Duration(24 * time.Hour)},
+	}
+}
+
+// LoadConfig returns the default configuration overridden by the file at path, unless path is empty,
+// and then by the AZURE_TTS_* environment variables. The result is validated and its style profiles
+// are registered, see RegisterStyleProfiles.
+func LoadConfig(path string) (*Config, error) {
+	cfg := DefaultConfig()
+	if path != "" {
+		if err := cfg.LoadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.LoadEnv(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if err := cfg.RegisterStyleProfiles(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// RegisterStyleProfiles loads the files of StyleProfiles and registers their profiles with
+// RegisterStyleProfile. The registry is shared by the whole process, so profiles of the same name
+// loaded from another configuration are replaced.
+func (c *Config) RegisterStyleProfiles() error {
+	for _, path := range c.StyleProfiles {
+		if err := LoadStyleProfiles(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadFile sets the fields present in the file at path. The format follows the extension: .yaml,
+// .yml, .json or .toml.
+func (c *Config) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config: %w", err)
+	}
+	if err := c.Decode(data, filepath.Ext(path)); err != nil {
+		return fmt.Errorf("parsing config %s: %w", path, err)
+	}
+	return nil
+}
+
+// Decode sets the fields present in data, in format json, yaml, yml or toml. Unknown fields are an error.
+func (c *Config) Decode(data []byte, format string) error {
+	switch strings.ToLower(strings.TrimPrefix(format, ".")) {
+	case "json":
+		return decodeJSON(data, c)
+	case "yaml", "yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		return nil
+	case "toml":
+		md, err := toml.NewDecoder(bytes.NewReader(data)).Decode(c)
+		if err != nil {
+			return err
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("unknown field %q", undecoded[0].String())
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported config format: %q", format)
+}
+
+func decodeJSON(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// LoadEnv sets the fields whose AZURE_TTS_* environment variable is set. The subscription key is also
+// read from AZURE_API_KEY when AZURE_TTS_KEY is not set.
+func (c *Config) LoadEnv() error {
+	if key, ok := os.LookupEnv("AZURE_API_KEY"); ok {
+		c.Key = key
+	}
+	return loadEnv(reflect.ValueOf(c).Elem(), envPrefix)
+}
+
+// loadEnv sets the fields of the struct v from the environment variables named prefix followed by the
+// upper-cased JSON name of the field.
+func loadEnv(v reflect.Value, prefix string) error {
+	textUnmarshaler := reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field, value := v.Type().Field(i), v.Field(i)
+		name := prefix + strings.ToUpper(strings.Split(field.Tag.Get("json"), ",")[0])
+		if field.Type.Kind() == reflect.Struct {
+			if err := loadEnv(value, name+"_"); err != nil {
+				return err
+			}
+			continue
+		}
+		env, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+
+		var err error
+		switch {
+		case reflect.PointerTo(field.Type).Implements(textUnmarshaler):
+			err = value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(env))
+		case field.Type.Kind() == reflect.String:
+			value.SetString(env)
+		case field.Type.Kind() == reflect.Int:
+			var n int
+			n, err = strconv.Atoi(env)
+			value.SetInt(int64(n))
+		case field.Type.Kind() == reflect.Float64:
+			var f float64
+			f, err = strconv.ParseFloat(env, 64)
+			value.SetFloat(f)
+		case field.Type.Kind() == reflect.Slice:
+			var items []string
+			for _, item := range strings.Split(env, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			value.Set(reflect.ValueOf(items))
+		default:
+			err = fmt.Errorf("unsupported type %s", field.Type)
+		}
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Validate reports every invalid field of the configuration.
+func (c *Config) Validate() error {
+	var errs []error
+	if c.Key == "" {
+		errs = append(errs, errors.New("key is required"))
+	}
+	if c.Region == "" {
+		errs = append(errs, errors.New("region is required"))
+	} else if _, err := model.ParseRegion(c.Region); err != nil {
+		errs = append(errs, err)
+	}
+	if c.Endpoint != "" && !isHTTPURL(c.Endpoint) {
+		errs = append(errs, fmt.Errorf("endpoint %q is not an http or https URL", c.Endpoint))
+	}
+	if c.DefaultVoice != "" {
+		if _, err := voiceLocale(c.DefaultVoice); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.Format != "" {
+		if _, err := model.StringToAudioOutput(c.Format); err != nil {
+			errs = append(errs, fmt.Errorf("format: %w", err))
+		}
+	}
+	durations := []struct {
+		name  string
+		value Duration
+	}{
+		{"timeout", c.Timeout}, {"token_timeout", c.TokenTimeout}, {"retry.backoff", c.Retry.Backoff},
+		{"cache.voice_list_ttl", c.Cache.VoiceListTTL},
+	}
+	for _, d := range durations {
+		if d.value < 0 {
+			errs = append(errs, fmt.Errorf("%s is negative", d.name))
+		}
+	}
+	if c.Retry.MaxAttempts < 0 {
+		errs = append(errs, errors.New("retry.max_attempts is negative"))
+	}
+	if c.RateLimit.RequestsPerSecond < 0 || c.RateLimit.Burst < 0 {
+		errs = append(errs, errors.New("rate_limit is negative"))
+	}
+	for _, path := range c.StyleProfiles {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".json", ".yaml", ".yml":
+		default:
+			errs = append(errs, fmt.Errorf("style profile file %q is not JSON or YAML", path))
+		}
+	}
+	for _, uri := range c.Lexicons {
+		if !isHTTPURL(uri) {
+			errs = append(errs, fmt.Errorf("lexicon %q is not an http or https URL", uri))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Dump returns the configuration as YAML with the key redacted, for debugging.
+func (c *Config) Dump() string {
+	redacted := *c
+	if n := len(redacted.Key); n > 4 {
+		redacted.Key = strings.Repeat("*", 8) + redacted.Key[n-4:]
+	} else if n > 0 {
+		redacted.Key = strings.Repeat("*", 8)
+	}
+	out, err := yaml.Marshal(&redacted)
+	if err != nil {
+		return fmt.Sprintf("config: %v", err)
+	}
+	return string(out)
+}
+
+// ClientOptions returns the client options for the configured endpoint, timeout, retries, caching,
+// rate limit and lexicons.
+func (c *Config) ClientOptions() []API.ClientOption {
+	var options []API.ClientOption
+	if c.Endpoint != "" {
+		endpoint := strings.TrimSuffix(c.Endpoint, "/")
+		options = append(options, func(az *API.AzureTTSClient) error {
+			az.TextToSpeechURL = endpoint + "/cognitiveservices/v1"
+			az.VoiceServiceListURL = endpoint + "/cognitiveservices/voices/list"
+			return nil
+		})
+	}
+	if c.Timeout > 0 {
+		options = append(options, API.WithTimeout(time.Duration(c.Timeout)))
+	}
+	if c.Retry.MaxAttempts > 1 {
+		backoff := time.Duration(c.Retry.Backoff)
+		if backoff == 0 {
+			backoff = defaultRetryBackoff
+		}
+		options = append(options, API.WithRetry(c.Retry.MaxAttempts, backoff))
+	}
+	if c.Cache.VoiceListTTL > 0 {
+		options = append(options, API.WithVoiceCatalogTTL(time.Duration(c.Cache.VoiceListTTL)))
+	}
+	if c.RateLimit.RequestsPerSecond > 0 {
+		options = append(options, API.WithRateLimit(c.RateLimit.RequestsPerSecond, c.RateLimit.Burst))
+	}
+	if len(c.Lexicons) > 0 {
+		options = append(options, API.WithLexicons(c.Lexicons...))
+	}
+	return options
+}
+
+// NewClientFromConfig validates cfg and builds a client with its options followed by options. The first
+// token is fetched within TokenTimeout. Style profiles are not registered, see RegisterStyleProfiles.
+func NewClientFromConfig(ctx context.Context, cfg *Config, options ...API.ClientOption) (*API.AzureTTSClient, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.TokenTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(cfg.TokenTimeout))
+		defer cancel()
+	}
+	region, err := model.ParseRegion(cfg.Region)
+	if err != nil {
+		return nil, err
+	}
+	return NewClientWithContext(ctx, cfg.Key, region, append(cfg.ClientOptions(), options...)...)
+}
+
+// NewRequest returns a request for text with the default voice, its locale, and the configured format.
+func (c *Config) NewRequest(text string) (*model.TextToSpeechRequest, error) {
+	request := &model.TextToSpeechRequest{SpeechText: text, VoiceName: c.DefaultVoice}
+	if c.DefaultVoice != "" {
+		locale, err := voiceLocale(c.DefaultVoice)
+		if err != nil {
+			return nil, err
+		}
+		request.Locale = locale
+	}
+	if c.Format != "" {
+		format, err := model.StringToAudioOutput(c.Format)
+		if err != nil {
+			return nil, err
+		}
+		request.AudioOutput = format
+	}
+	return request, nil
+}
+
+// voiceLocale returns the locale prefix of a voice short name such as zh-TW-HsiaoChenNeural.
+func voiceLocale(voice string) (model.Locale, error) {
+	parts := strings.SplitN(voice, "-", 3)
+	if len(parts) < 3 {
+		return "", fmt.Errorf("can't derive the locale from voice %q", voice)
+	}
+	return model.ParseLocale(parts[0] + "-" + parts[1])
+}
+
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != ""
+}
@@ -0,0 +1,215 @@
+package azuretts_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tts "github.com/barkingdog-ai/azure-tts"
+	API "github.com/barkingdog-ai/azure-tts/api"
+	"github.com/barkingdog-ai/azure-tts/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigDecode(t *testing.T) {
+	files := map[string]string{
+		"yaml": `
+key: secret
+region: eastasia
+default_voice: zh-TW-HsiaoChenNeural
+timeout: 10s
+retry:
+  max_attempts: 3
+  backoff: 200ms
+rate_limit:
+  requests_per_second: 2.5
+  burst: 5
+style_profiles: [styles.yaml]
+lexicons: ["https://example.com/lexicon.xml"]
+`,
+		"json": `{
+	"key": "secret", "region": "eastasia", "default_voice": "zh-TW-HsiaoChenNeural", "timeout": "10s",
+	"retry": {"max_attempts": 3, "backoff": "200ms"},
+	"rate_limit": {"requests_per_second": 2.5, "burst": 5},
+	"style_profiles": ["styles.yaml"], "lexicons": ["https://example.com/lexicon.xml"]
+}`,
+		"toml": `
+# Azure TTS
+key = """secret"""
+region = 'eastasia'
+default_voice = "zh-TW-HsiaoChenNeural" # the default
+timeout = "10s"
+style_profiles = [
+  "styles.yaml",
+]
+lexicons = ["https://example.com/lexicon.xml"]
+rate_limit = { requests_per_second = 2.5, burst = 5 }
+
+[retry]
+max_attempts = 3
+backoff = "200ms"
+`,
+	}
+
+	want := tts.DefaultConfig()
+	want.Key = "secret"
+	want.Region = "eastasia"
+	want.DefaultVoice = "zh-TW-HsiaoChenNeural"
+	want.Timeout = tts.Duration(10 * time.Second)
+	want.Retry = tts.RetryConfig{MaxAttempts: 3, Backoff: tts.Duration(200 * time.Millisecond)}
+	want.RateLimit = tts.RateLimitConfig{RequestsPerSecond: 2.5, Burst: 5}
+	want.StyleProfiles = []string{"styles.yaml"}
+	want.Lexicons = []string{"https://example.com/lexicon.xml"}
+
+	for format, data := range files {
+		t.Run(format, func(t *testing.T) {
+			cfg := tts.DefaultConfig()
+			require.NoError(t, cfg.Decode([]byte(data), format))
+			assert.Equal(t, want, cfg)
+		})
+	}
+
+	cfg := tts.DefaultConfig()
+	assert.Error(t, cfg.Decode([]byte("regoin: eastasia"), "yaml"), "unknown field")
+	assert.Error(t, cfg.Decode([]byte(`{"timeout": 10}`), "json"))
+	assert.Error(t, cfg.Decode([]byte("key = \"a\"\nkey = \"b\""), "toml"), "duplicate key")
+	assert.Error(t, cfg.Decode([]byte("key = \"unterminated"), "toml"))
+	assert.Error(t, cfg.Decode([]byte("key = secret"), "toml"))
+	assert.Error(t, cfg.Decode([]byte("[retry]\nattempts = 3"), "toml"), "unknown field")
+	assert.Error(t, cfg.Decode(nil, "ini"))
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "azuretts.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("key: from-file\nregion: eastasia\nretry:\n  max_attempts: 2\n"), 0o600))
+
+	t.Setenv("AZURE_API_KEY", "legacy")
+	t.Setenv("AZURE_TTS_REGION", "westus2")
+	t.Setenv("AZURE_TTS_RETRY_BACKOFF", "1s")
+	t.Setenv("AZURE_TTS_RATE_LIMIT_REQUESTS_PER_SECOND", "4")
+	t.Setenv("AZURE_TTS_LEXICONS", "https://example.com/a.xml, https://example.com/b.xml")
+	cfg, err := tts.LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, "legacy", cfg.Key)
+	assert.Equal(t, "westus2", cfg.Region)
+	assert.Equal(t, tts.RetryConfig{MaxAttempts: 2, Backoff: tts.Duration(time.Second)}, cfg.Retry)
+	assert.Equal(t, 4.0, cfg.RateLimit.RequestsPerSecond)
+	assert.Equal(t, []string{"https://example.com/a.xml", "https://example.com/b.xml"}, cfg.Lexicons)
+
+	t.Setenv("AZURE_TTS_KEY", "from-env")
+	cfg, err = tts.LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", cfg.Key)
+
+	t.Setenv("AZURE_TTS_TIMEOUT", "soon")
+	_, err = tts.LoadConfig(path)
+	assert.ErrorContains(t, err, "AZURE_TTS_TIMEOUT")
+
+	_, err = tts.LoadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestLoadConfigStyleProfiles(t *testing.T) {
+	dir := t.TempDir()
+	styles := filepath.Join(dir, "styles.yaml")
+	require.NoError(t, os.WriteFile(styles, []byte("- name: config-test\n  style: calm\n"), 0o600))
+	path := filepath.Join(dir, "azuretts.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("key: k\nregion: eastasia\nstyle_profiles: ["+styles+"]\n"), 0o600))
+	t.Cleanup(func() { delete(tts.PredefinedStyles, "config-test") })
+
+	cfg, err := tts.LoadConfig(path)
+	require.NoError(t, err)
+	profile, err := tts.GetStyleProfile("config-test")
+	require.NoError(t, err)
+	assert.Equal(t, "calm", profile.Style)
+
+	delete(tts.PredefinedStyles, "config-test")
+	az, err := tts.NewClientFromConfig(context.Background(), cfg, API.WithStaticToken("token"))
+	require.NoError(t, err)
+	defer az.Close(context.Background())
+	_, err = tts.GetStyleProfile("config-test")
+	assert.Error(t, err, "building a client leaves the registry alone")
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := tts.DefaultConfig()
+	cfg.Region = "east asia"
+	cfg.Endpoint = "eastasia.tts.speech.microsoft.com"
+	cfg.DefaultVoice = "HsiaoChen"
+	cfg.Format = "wav"
+	cfg.Timeout = tts.Duration(-time.Second)
+	cfg.StyleProfiles = []string{"styles.toml"}
+	cfg.Lexicons = []string{"lexicon.xml"}
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	for _, problem := range []string{
+		"key is required", "invalid region", "endpoint", "HsiaoChen", "format", "timeout is negative",
+		"styles.toml", "lexicon.xml",
+	} {
+		assert.ErrorContains(t, err, problem)
+	}
+}
+
+func TestConfigDump(t *testing.T) {
+	cfg := tts.DefaultConfig()
+	cfg.Key = "0123456789abcdef"
+	cfg.Region = "eastasia"
+
+	dump := cfg.Dump()
+	assert.NotContains(t, dump, "0123456789")
+	assert.Contains(t, dump, "key: '********cdef'")
+	assert.Contains(t, dump, "timeout: 30s")
+	assert.Equal(t, "0123456789abcdef", cfg.Key, "the config itself is left alone")
+}
+
+func TestNewClientFromConfig(t *testing.T) {
+	var attempts atomic.Int32
+	var ssml string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/cognitiveservices/v1", r.URL.Path)
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		ssml = string(body)
+		_, _ = w.Write([]byte("audio"))
+	}))
+	defer srv.Close()
+
+	cfg := tts.DefaultConfig()
+	cfg.Key = "key"
+	cfg.Region = "eastasia"
+	cfg.Endpoint = srv.URL + "/"
+	cfg.DefaultVoice = "zh-TW-HsiaoChenNeural"
+	cfg.Retry = tts.RetryConfig{MaxAttempts: 2, Backoff: tts.Duration(time.Millisecond)}
+	cfg.Lexicons = []string{"https://example.com/lexicon.xml"}
+
+	az, err := tts.NewClientFromConfig(context.Background(), &cfg, API.WithStaticToken("token"))
+	require.NoError(t, err)
+	defer az.Close(context.Background())
+	assert.Equal(t, model.RegionEastAsia, az.Region, "the region is kept with a custom endpoint")
+
+	request, err := cfg.NewRequest("你好")
+	require.NoError(t, err)
+	assert.Equal(t, model.LocaleZhTW, request.Locale)
+	assert.Equal(t, model.Audio16khz32kbitrateMonoMp3, request.AudioOutput)
+
+	audio, err := az.TextToSpeech(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, "audio", string(audio))
+	assert.Equal(t, int32(2), attempts.Load())
+	assert.Contains(t, ssml, `<lexicon uri="https://example.com/lexicon.xml"/>你好`)
+
+	cfg.Key = ""
+	_, err = tts.NewClientFromConfig(context.Background(), &cfg)
+	assert.ErrorContains(t, err, "key is required")
+}
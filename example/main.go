@@ -20,7 +20,7 @@ func main() {
 	if err != nil {
 		exit(fmt.Errorf("failed to create new client, received %v", err))
 	}
-	defer close(az.TokenRefreshDoneCh)
+	defer az.Close(context.Background())
 
 	ctx := context.Background()
 
@@ -20,7 +20,7 @@ func main() {
 	if err != nil {
 		exit(fmt.Errorf("failed to create new client, received %v", err))
 	}
-	defer close(az.TokenRefreshDoneCh)
+	defer az.Close(context.Background())
 
 	ctx := context.Background()
 
@@ -67,7 +67,6 @@ func main() {
 	}
 
 	voiceName := "zh-TW-HsiaoChenNeural"
-	locale := "zh-TW"
 
 	for _, example := range examples {
 		fmt.Printf("正在生成: %s\n", example.name)
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// DialogueSpeaker holds the voice settings shared by every turn of one speaker in a dialogue.
+type DialogueSpeaker struct {
+	VoiceName string
+	Locale    Locale
+	Style     *TTSStyle
+	Role      string // e.g. YoungAdultFemale, OlderAdultMale
+}
+
+// DialogueTurn is a single utterance in a dialogue. Fields left empty fall back to the
+// settings registered for Speaker in DialogueRequest.Speakers.
+type DialogueTurn struct {
+	Speaker    string
+	VoiceName  string
+	Locale     Locale
+	Style      *TTSStyle
+	Role       string
+	Text       string
+	PauseAfter time.Duration
+}
+
+// DialogueRequest describes a multi-voice conversation that is synthesized into a single audio stream.
+type DialogueRequest struct {
+	Speakers    map[string]DialogueSpeaker
+	Turns       []DialogueTurn
+	AudioOutput AudioOutput
+	Homophones  []Homophones
+}
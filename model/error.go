@@ -1,11 +1,46 @@
 package model
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Sentinel errors for the failures callers usually handle differently. Errors returned by the
+// client match them with errors.Is; use errors.As with *APIError or *NetworkError for the details.
+var (
+	// ErrUnauthorized means the subscription key or token was rejected (401 or 403).
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrThrottled means the quota or rate limit was exceeded (429); see APIError.RetryAfter.
+	ErrThrottled = errors.New("throttled")
+	// ErrPayloadTooLarge means the request body was too large for the service (413).
+	ErrPayloadTooLarge = errors.New("payload too large")
+	// ErrInvalidSSML means the synthesis service rejected the SSML document (400).
+	ErrInvalidSSML = errors.New("invalid SSML")
+	// ErrNoMatch means speech recognition found no recognizable speech in the audio.
+	ErrNoMatch = errors.New("no speech recognized")
+	// ErrNetwork means the service could not be reached.
+	ErrNetwork = errors.New("network error")
+	// ErrTimeout means the request timed out or its context deadline passed.
+	ErrTimeout = errors.New("timeout")
+	// ErrNoVoice means no voice of the voice list speaks the requested language with the required features.
+	ErrNoVoice = errors.New("no matching voice")
+	// ErrClientClosed is returned by every call made after the client was closed.
+	ErrClientClosed = errors.New("client closed")
+)
 
 type APIError struct {
 	StatusCode int    `json:"status_code"`
 	Message    string `json:"message"`
 	Type       string `json:"type"`
+	// RequestID is the X-RequestId of the failed response, needed when contacting Azure support.
+	RequestID string `json:"request_id,omitempty"`
+	// Region is the Azure region the request was sent to.
+	Region string `json:"region,omitempty"`
+	// RetryAfter is how long the service asked to wait before retrying, from the Retry-After header.
+	RetryAfter time.Duration `json:"-"`
 }
 
 type APIErrorResponse struct {
@@ -13,5 +48,94 @@ type APIErrorResponse struct {
 }
 
 func (e APIError) Error() string {
+	if e.RequestID != "" {
+		return fmt.Sprintf("[%d:%s] %s (request id %s)", e.StatusCode, e.Type, e.Message, e.RequestID)
+	}
 	return fmt.Sprintf("[%d:%s] %s", e.StatusCode, e.Type, e.Message)
 }
+
+// Is reports whether the status code of e corresponds to target.
+func (e APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrThrottled:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrPayloadTooLarge:
+		return e.StatusCode == http.StatusRequestEntityTooLarge
+	}
+	return false
+}
+
+// NetworkError reports a request that failed before a response was received.
+type NetworkError struct {
+	Region string
+	Err    error
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("request to region %s failed: %v", e.Region, e.Err)
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches ErrNetwork, and ErrTimeout when the underlying error is a timeout.
+func (e *NetworkError) Is(target error) bool {
+	switch target {
+	case ErrNetwork:
+		return true
+	case ErrTimeout:
+		var timeout interface{ Timeout() bool }
+		return errors.Is(e.Err, context.DeadlineExceeded) || (errors.As(e.Err, &timeout) && timeout.Timeout())
+	}
+	return false
+}
+
+// RecognitionError reports a speech recognition request that completed without recognized text.
+// It matches ErrNoMatch.
+type RecognitionError struct {
+	// Status is the RecognitionStatus of the response, e.g. NoMatch or InitialSilenceTimeout.
+	Status string
+}
+
+func (e *RecognitionError) Error() string {
+	return fmt.Sprintf("speech recognition failed: %s", e.Status)
+}
+
+func (e *RecognitionError) Is(target error) bool {
+	return target == ErrNoMatch
+}
+
+// RetryAfter returns how long the service asked to wait before retrying the request that failed with err.
+func RetryAfter(err error) (time.Duration, bool) {
+	var apiErr APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter, true
+	}
+	return 0, false
+}
+
+// ProsodyError reports a prosody setting the service would reject.
+type ProsodyError struct {
+	Field  string
+	Value  string
+	Reason string
+}
+
+func (e *ProsodyError) Error() string {
+	return fmt.Sprintf("invalid prosody %s %q: %s", e.Field, e.Value, e.Reason)
+}
+
+// MarkupError reports a syntax error in the inline markup of TextToSpeechRequest.SpeechText.
+// Line and Column are 1-based and count characters, not bytes.
+type MarkupError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *MarkupError) Error() string {
+	return fmt.Sprintf("markup error at line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
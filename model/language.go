@@ -0,0 +1,189 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Script is a writing system detected in text.
+type Script string
+
+const (
+	ScriptHan      Script = "Han"
+	ScriptKana     Script = "Kana"
+	ScriptHangul   Script = "Hangul"
+	ScriptLatin    Script = "Latin"
+	ScriptCyrillic Script = "Cyrillic"
+	ScriptThai     Script = "Thai"
+	ScriptArabic   Script = "Arabic"
+)
+
+// defaultScriptLocales is the locale a script is spoken in when it isn't the script of the request locale.
+var defaultScriptLocales = map[Script]Locale{
+	ScriptHan:      LocaleZhCN,
+	ScriptKana:     LocaleJaJP,
+	ScriptHangul:   LocaleKoKR,
+	ScriptLatin:    LocaleEnUS,
+	ScriptCyrillic: LocaleRuRU,
+	ScriptThai:     LocaleThTH,
+	ScriptArabic:   LocaleArSA,
+}
+
+// languageScripts lists the scripts of the languages not written in the Latin script.
+var languageScripts = map[string][]Script{
+	"zh":  {ScriptHan},
+	"yue": {ScriptHan},
+	"wuu": {ScriptHan},
+	"ja":  {ScriptHan, ScriptKana},
+	"ko":  {ScriptHangul},
+	"ru":  {ScriptCyrillic},
+	"uk":  {ScriptCyrillic},
+	"bg":  {ScriptCyrillic},
+	"th":  {ScriptThai},
+	"ar":  {ScriptArabic},
+	"fa":  {ScriptArabic},
+}
+
+// reAmount matches an amount whose only letters are its currency prefix, e.g. NT$1,200 or US$ 5.
+var reAmount = regexp.MustCompile(`^\s*[A-Z]{1,3}\$\s?\d[\d,.]*\s*$`)
+
+// LanguageDetection enables text mixing languages, e.g. Chinese with English product names, to be
+// spoken in the language of each part. Runs in the script of the request locale are spoken in it;
+// runs in other scripts are spoken in the locale of their script.
+type LanguageDetection struct {
+	// Locales maps a script to the locale its runs are spoken in, overriding the request locale and the
+	// defaults: en-US for Latin, zh-CN for Han, ja-JP for Kana, ko-KR for Hangul, ru-RU for Cyrillic,
+	// th-TH for Thai and ar-SA for Arabic.
+	Locales map[Script]Locale `json:"locales" yaml:"locales"`
+	// Voices maps a locale to the voice speaking its runs. Runs in a locale without a voice are wrapped
+	// in <lang xml:lang> for the request voice, which must be multilingual to speak them.
+	Voices map[Locale]string `json:"voices" yaml:"voices"`
+}
+
+// LanguageRun is a part of a text written in one script, and the locale it is spoken in.
+type LanguageRun struct {
+	Text   string
+	Script Script
+	Locale Locale
+}
+
+// Runs splits text into runs by script and assigns each its locale; locale is the locale of the request.
+// Digits, punctuation and spaces belong to the run before them, or to the first run at the start of the
+// text, as do amounts with a currency prefix such as NT$1,200, and Han characters next to kana are read
+// as Japanese. Adjacent runs spoken in the same locale
+// are joined.
+func (d *LanguageDetection) Runs(text string, locale Locale) []LanguageRun {
+	var runs []LanguageRun
+	var leading strings.Builder
+	for _, r := range text {
+		script, ok := runeScript(r)
+		switch {
+		case !ok && len(runs) == 0:
+			leading.WriteRune(r)
+		case len(runs) > 0 && (!ok || runs[len(runs)-1].Script == script):
+			runs[len(runs)-1].Text += string(r)
+		default:
+			runs = append(runs, LanguageRun{Text: string(r), Script: script})
+		}
+	}
+	if len(runs) == 0 {
+		if text == "" {
+			return nil
+		}
+		return []LanguageRun{{Text: text, Locale: locale}}
+	}
+	runs[0].Text = leading.String() + runs[0].Text
+	runs = joinAmounts(runs)
+
+	for i := range runs {
+		if runs[i].Script == ScriptHan &&
+			(i > 0 && runs[i-1].Script == ScriptKana || i+1 < len(runs) && runs[i+1].Script == ScriptKana) {
+			runs[i].Locale = d.scriptLocale(ScriptKana, locale)
+			continue
+		}
+		runs[i].Locale = d.scriptLocale(runs[i].Script, locale)
+	}
+
+	joined := runs[:1]
+	for _, run := range runs[1:] {
+		if last := &joined[len(joined)-1]; last.Locale == run.Locale {
+			last.Text += run.Text
+			continue
+		}
+		joined = append(joined, run)
+	}
+	return joined
+}
+
+// joinAmounts joins runs holding only an amount with a currency prefix to the run before them, or
+// to the run after them at the start of the text.
+func joinAmounts(runs []LanguageRun) []LanguageRun {
+	if len(runs) < 2 {
+		return runs
+	}
+	joined := make([]LanguageRun, 0, len(runs))
+	var pending string
+	for _, run := range runs {
+		if reAmount.MatchString(run.Text) {
+			if len(joined) > 0 {
+				joined[len(joined)-1].Text += run.Text
+			} else {
+				pending += run.Text
+			}
+			continue
+		}
+		run.Text = pending + run.Text
+		pending = ""
+		if last := len(joined) - 1; last >= 0 && joined[last].Script == run.Script {
+			joined[last].Text += run.Text
+			continue
+		}
+		joined = append(joined, run)
+	}
+	if len(joined) == 0 {
+		return runs
+	}
+	return joined
+}
+
+// scriptLocale returns the locale the runs in script are spoken in.
+func (d *LanguageDetection) scriptLocale(script Script, locale Locale) Locale {
+	if d != nil {
+		if l, ok := d.Locales[script]; ok {
+			return l
+		}
+	}
+	scripts, ok := languageScripts[locale.Language()]
+	if !ok {
+		scripts = []Script{ScriptLatin}
+	}
+	for _, s := range scripts {
+		if s == script {
+			return locale
+		}
+	}
+	return defaultScriptLocales[script]
+}
+
+// runeScript returns the script of r. It reports false for characters of no particular script such as
+// digits, punctuation, symbols and spaces. CJK punctuation counts as Han.
+func runeScript(r rune) (Script, bool) {
+	switch {
+	case unicode.Is(unicode.Han, r), r >= 0x3000 && r <= 0x303F, r >= 0xFF00 && r <= 0xFF0F, r >= 0xFF1A && r <= 0xFF20:
+		return ScriptHan, true
+	case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+		return ScriptKana, true
+	case unicode.Is(unicode.Hangul, r):
+		return ScriptHangul, true
+	case unicode.Is(unicode.Latin, r):
+		return ScriptLatin, true
+	case unicode.Is(unicode.Cyrillic, r):
+		return ScriptCyrillic, true
+	case unicode.Is(unicode.Thai, r):
+		return ScriptThai, true
+	case unicode.Is(unicode.Arabic, r):
+		return ScriptArabic, true
+	}
+	return "", false
+}
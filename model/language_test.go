@@ -0,0 +1,84 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLanguageDetectionRuns(t *testing.T) {
+	tests := []struct {
+		name      string
+		detection *model.LanguageDetection
+		text      string
+		locale    model.Locale
+		want      []model.LanguageRun
+	}{
+		{
+			name:   "chinese with english terms",
+			text:   "請登入你的 Dashboard 查看 invoice。",
+			locale: model.LocaleZhTW,
+			want: []model.LanguageRun{
+				{Text: "請登入你的 ", Script: model.ScriptHan, Locale: model.LocaleZhTW},
+				{Text: "Dashboard ", Script: model.ScriptLatin, Locale: model.LocaleEnUS},
+				{Text: "查看 ", Script: model.ScriptHan, Locale: model.LocaleZhTW},
+				{Text: "invoice", Script: model.ScriptLatin, Locale: model.LocaleEnUS},
+				{Text: "。", Script: model.ScriptHan, Locale: model.LocaleZhTW},
+			},
+		},
+		{
+			name:      "configured locale and leading digits",
+			detection: &model.LanguageDetection{Locales: map[model.Script]model.Locale{model.ScriptLatin: model.LocaleEnGB}},
+			text:      "3 apples 和 Windows 11",
+			locale:    model.LocaleZhCN,
+			want: []model.LanguageRun{
+				{Text: "3 apples ", Script: model.ScriptLatin, Locale: model.LocaleEnGB},
+				{Text: "和 ", Script: model.ScriptHan, Locale: model.LocaleZhCN},
+				{Text: "Windows 11", Script: model.ScriptLatin, Locale: model.LocaleEnGB},
+			},
+		},
+		{
+			name:   "han next to kana is japanese",
+			text:   "Hello 東京へようこそ",
+			locale: model.LocaleEnUS,
+			want: []model.LanguageRun{
+				{Text: "Hello ", Script: model.ScriptLatin, Locale: model.LocaleEnUS},
+				{Text: "東京へようこそ", Script: model.ScriptHan, Locale: model.LocaleJaJP},
+			},
+		},
+		{
+			name:   "amount with a currency prefix",
+			text:   "價格 NT$1,200 元",
+			locale: model.LocaleZhTW,
+			want:   []model.LanguageRun{{Text: "價格 NT$1,200 元", Script: model.ScriptHan, Locale: model.LocaleZhTW}},
+		},
+		{
+			name:   "leading amount",
+			text:   "US$5 的 plan",
+			locale: model.LocaleZhTW,
+			want: []model.LanguageRun{
+				{Text: "US$5 的 ", Script: model.ScriptHan, Locale: model.LocaleZhTW},
+				{Text: "plan", Script: model.ScriptLatin, Locale: model.LocaleEnUS},
+			},
+		},
+		{
+			name:   "single script",
+			text:   "Just English.",
+			locale: model.LocaleEnUS,
+			want:   []model.LanguageRun{{Text: "Just English.", Script: model.ScriptLatin, Locale: model.LocaleEnUS}},
+		},
+		{
+			name:   "no script",
+			text:   "123!",
+			locale: model.LocaleZhTW,
+			want:   []model.LanguageRun{{Text: "123!", Locale: model.LocaleZhTW}},
+		},
+		{name: "empty", locale: model.LocaleZhTW},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.detection.Runs(tt.text, tt.locale))
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Locale is a BCP-47 language tag such as zh-TW or yue-CN, the language or locale for text-to-speech.
+// Any valid tag is accepted, the constants below are well-known values.
+// See "locale" in https://learn.microsoft.com/en-us/azure/ai-services/speech-service/language-support
+type Locale string
+
+const (
+	LocaleArEG  Locale = "ar-EG"
+	LocaleArSA  Locale = "ar-SA"
+	LocaleBgBG  Locale = "bg-BG"
+	LocaleCaES  Locale = "ca-ES"
+	LocaleCsCZ  Locale = "cs-CZ"
+	LocaleDaDK  Locale = "da-DK"
+	LocaleDeAT  Locale = "de-AT"
+	LocaleDeCH  Locale = "de-CH"
+	LocaleDeDE  Locale = "de-DE"
+	LocaleElGR  Locale = "el-GR"
+	LocaleEnAU  Locale = "en-AU"
+	LocaleEnCA  Locale = "en-CA"
+	LocaleEnGB  Locale = "en-GB"
+	LocaleEnIE  Locale = "en-IE"
+	LocaleEnIN  Locale = "en-IN"
+	LocaleEnUS  Locale = "en-US"
+	LocaleEsES  Locale = "es-ES"
+	LocaleEsMX  Locale = "es-MX"
+	LocaleEtEE  Locale = "et-EE"
+	LocaleFiFI  Locale = "fi-FI"
+	LocaleFrCA  Locale = "fr-CA"
+	LocaleFrCH  Locale = "fr-CH"
+	LocaleFrFR  Locale = "fr-FR"
+	LocaleGaIE  Locale = "ga-IE"
+	LocaleHeIL  Locale = "he-IL"
+	LocaleHiIN  Locale = "hi-IN"
+	LocaleHrHR  Locale = "hr-HR"
+	LocaleHuHU  Locale = "hu-HU"
+	LocaleIDID  Locale = "id-ID"
+	LocaleItIT  Locale = "it-IT"
+	LocaleJaJP  Locale = "ja-JP"
+	LocaleKoKR  Locale = "ko-KR"
+	LocaleLtLT  Locale = "lt-LT"
+	LocaleLvLV  Locale = "lv-LV"
+	LocaleMtMT  Locale = "mt-MT"
+	LocaleMrIN  Locale = "mr-IN"
+	LocaleMsMY  Locale = "ms-MY"
+	LocaleNbNO  Locale = "nb-NO"
+	LocaleNlNL  Locale = "nl-NL"
+	LocalePlPL  Locale = "pl-PL"
+	LocalePtBR  Locale = "pt-BR"
+	LocalePtPT  Locale = "pt-PT"
+	LocaleRoRO  Locale = "ro-RO"
+	LocaleRuRU  Locale = "ru-RU"
+	LocaleSkSK  Locale = "sk-SK"
+	LocaleSlSI  Locale = "sl-SI"
+	LocaleSvSE  Locale = "sv-SE"
+	LocaleTaIN  Locale = "ta-IN"
+	LocaleTeIN  Locale = "te-IN"
+	LocaleThTH  Locale = "th-TH"
+	LocaleTrTR  Locale = "tr-TR"
+	LocaleViVN  Locale = "vi-VN"
+	LocaleZhCN  Locale = "zh-CN"
+	LocaleZhHK  Locale = "zh-HK"
+	LocaleZhTW  Locale = "zh-TW"
+	LocaleFilPH Locale = "fil-PH"
+	LocaleTaMY  Locale = "ta-MY"
+	LocaleKmKH  Locale = "km-KH"
+	LocaleYueCN Locale = "yue-CN"
+	LocaleWuuCN Locale = "wuu-CN"
+)
+
+// knownLocales lists the Locale constants.
+var knownLocales = []Locale{
+	LocaleArEG,
+	LocaleArSA,
+	LocaleBgBG,
+	LocaleCaES,
+	LocaleCsCZ,
+	LocaleDaDK,
+	LocaleDeAT,
+	LocaleDeCH,
+	LocaleDeDE,
+	LocaleElGR,
+	LocaleEnAU,
+	LocaleEnCA,
+	LocaleEnGB,
+	LocaleEnIE,
+	LocaleEnIN,
+	LocaleEnUS,
+	LocaleEsES,
+	LocaleEsMX,
+	LocaleEtEE,
+	LocaleFiFI,
+	LocaleFrCA,
+	LocaleFrCH,
+	LocaleFrFR,
+	LocaleGaIE,
+	LocaleHeIL,
+	LocaleHiIN,
+	LocaleHrHR,
+	LocaleHuHU,
+	LocaleIDID,
+	LocaleItIT,
+	LocaleJaJP,
+	LocaleKoKR,
+	LocaleLtLT,
+	LocaleLvLV,
+	LocaleMtMT,
+	LocaleMrIN,
+	LocaleMsMY,
+	LocaleNbNO,
+	LocaleNlNL,
+	LocalePlPL,
+	LocalePtBR,
+	LocalePtPT,
+	LocaleRoRO,
+	LocaleRuRU,
+	LocaleSkSK,
+	LocaleSlSI,
+	LocaleSvSE,
+	LocaleTaIN,
+	LocaleTeIN,
+	LocaleThTH,
+	LocaleTrTR,
+	LocaleViVN,
+	LocaleZhCN,
+	LocaleZhHK,
+	LocaleZhTW,
+	LocaleFilPH,
+	LocaleTaMY,
+	LocaleKmKH,
+	LocaleYueCN,
+	LocaleWuuCN,
+}
+
+func (l Locale) String() string {
+	return string(l)
+}
+
+// ParseLocale parses a BCP-47 language tag made of a language, an optional script, an optional
+// region and variants, e.g. "zh-TW", "iu-Cans-CA" or "zh-CN-sichuan". Underscores are accepted as
+// separators and the parts are returned in their canonical case.
+func ParseLocale(s string) (Locale, error) {
+	parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(s), "_", "-"), "-")
+	if !isAlpha(parts[0]) || len(parts[0]) < 2 || len(parts[0]) > 8 || len(parts[0]) == 4 {
+		return "", fmt.Errorf("invalid locale: %q", s)
+	}
+	parts[0] = strings.ToLower(parts[0])
+
+	i := 1
+	if i < len(parts) && len(parts[i]) == 4 && isAlpha(parts[i]) {
+		parts[i] = strings.ToUpper(parts[i][:1]) + strings.ToLower(parts[i][1:])
+		i++
+	}
+	if i < len(parts) && (len(parts[i]) == 2 && isAlpha(parts[i]) || len(parts[i]) == 3 && isDigits(parts[i])) {
+		parts[i] = strings.ToUpper(parts[i])
+		i++
+	}
+	for ; i < len(parts); i++ {
+		variant := parts[i]
+		if !isAlphanumeric(variant) || !(len(variant) >= 5 && len(variant) <= 8 || len(variant) == 4 && isDigits(variant[:1])) {
+			return "", fmt.Errorf("invalid locale: %q", s)
+		}
+		parts[i] = strings.ToLower(variant)
+	}
+	return Locale(strings.Join(parts, "-")), nil
+}
+
+// LocaleString parses s like ParseLocale.
+//
+// Deprecated: use ParseLocale.
+func LocaleString(s string) (Locale, error) {
+	return ParseLocale(s)
+}
+
+// LocaleValues returns the well-known locales.
+func LocaleValues() []Locale {
+	return append([]Locale(nil), knownLocales...)
+}
+
+// LocaleStrings returns the well-known locales as strings.
+func LocaleStrings() []string {
+	out := make([]string, len(knownLocales))
+	for i, l := range knownLocales {
+		out[i] = string(l)
+	}
+	return out
+}
+
+// IsALocale reports whether l is one of the well-known locales.
+func (l Locale) IsALocale() bool {
+	for _, known := range knownLocales {
+		if l == known {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValid reports whether l is a valid language tag in canonical case.
+func (l Locale) IsValid() bool {
+	parsed, err := ParseLocale(string(l))
+	return err == nil && parsed == l
+}
+
+// Language returns the language subtag, e.g. "zh" for zh-TW.
+func (l Locale) Language() string {
+	language, _, _ := strings.Cut(string(l), "-")
+	return language
+}
+
+// Script returns the script subtag, e.g. "Hant" for zh-Hant-TW, or "" when the tag has none.
+func (l Locale) Script() string {
+	for _, part := range strings.Split(string(l), "-")[1:] {
+		if len(part) == 4 && isAlpha(part) {
+			return part
+		}
+	}
+	return ""
+}
+
+// Region returns the region subtag, e.g. "TW" for zh-TW, or "" when the tag has none.
+func (l Locale) Region() string {
+	for _, part := range strings.Split(string(l), "-")[1:] {
+		if len(part) == 2 || len(part) == 3 && isDigits(part) {
+			return part
+		}
+	}
+	return ""
+}
+
+// LocalesFromVoices returns the primary and secondary locales of voices, e.g. of the live voice
+// list, sorted and without duplicates. Invalid tags are skipped.
+func LocalesFromVoices(voices []VoiceListResponse) []Locale {
+	seen := map[Locale]bool{}
+	var out []Locale
+	add := func(s string) {
+		if l, err := ParseLocale(s); err == nil && !seen[l] {
+			seen[l] = true
+			out = append(out, l)
+		}
+	}
+	for i := range voices {
+		add(voices[i].Locale)
+		for _, s := range voices[i].SecondaryLocaleList {
+			add(s)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+func isAlpha(s string) bool {
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isAlphanumeric(s string) bool {
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,72 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLocale(t *testing.T) {
+	tests := []struct {
+		input    string
+		want     model.Locale
+		language string
+		region   string
+		wantErr  bool
+	}{
+		{input: "zh-TW", want: model.LocaleZhTW, language: "zh", region: "TW"},
+		{input: "yue-cn", want: model.LocaleYueCN, language: "yue", region: "CN"},
+		{input: "wuu_CN", want: "wuu-CN", language: "wuu", region: "CN"},
+		{input: "iu-cans-ca", want: "iu-Cans-CA", language: "iu", region: "CA"},
+		{input: "zh-CN-Sichuan", want: "zh-CN-sichuan", language: "zh", region: "CN"},
+		{input: "es-419", want: "es-419", language: "es", region: "419"},
+		{input: "en", want: "en", language: "en"},
+		{input: "", wantErr: true},
+		{input: "e", wantErr: true},
+		{input: "zh-TW-x", wantErr: true},
+		{input: "zh TW", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := model.ParseLocale(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.True(t, got.IsValid())
+			assert.Equal(t, tt.language, got.Language())
+			assert.Equal(t, tt.region, got.Region())
+		})
+	}
+
+	assert.True(t, model.LocaleKmKH.IsALocale())
+	assert.False(t, model.Locale("wuu-TW").IsALocale())
+	assert.False(t, model.Locale("zh-tw").IsValid(), "not in canonical case")
+}
+
+func TestParseRegion(t *testing.T) {
+	got, err := model.ParseRegion("SwedenCentral")
+	assert.NoError(t, err)
+	assert.Equal(t, model.RegionSwedenCentral, got)
+
+	got, err = model.ParseRegion("newregion2")
+	assert.NoError(t, err)
+	assert.Equal(t, "newregion2", got.String())
+
+	for _, invalid := range []string{"", "east asia", "eastasia.example.com", "2east"} {
+		_, err := model.ParseRegion(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestLocalesFromVoices(t *testing.T) {
+	voices := []model.VoiceListResponse{
+		{ShortName: "zh-CN-XiaoxiaoMultilingualNeural", Locale: "zh-CN", SecondaryLocaleList: []string{"en-US", "yue-CN"}},
+		{ShortName: "yue-CN-XiaoMinNeural", Locale: "yue-CN"},
+		{ShortName: "broken", Locale: "??"},
+	}
+	assert.Equal(t, []model.Locale{model.LocaleEnUS, model.LocaleYueCN, model.LocaleZhCN}, model.LocalesFromVoices(voices))
+}
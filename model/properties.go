@@ -1,6 +1,9 @@
 package model
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // AudioOutput types represent the supported audio encoding formats for the text-to-speech endpoint.
 // This type is required when requesting to azuretexttospeech.Synthesize text-to-speed request.
@@ -24,6 +27,7 @@ const (
 	Audio16khz128kbitrateMonoMp3
 	Audio24khz48kbitrateMonoMp3
 	Audio24khz96kbitrateMonoMp3
+	AudioRAW8Bit8kHzMonoAlaw
 )
 
 func (a AudioOutput) String() string {
@@ -43,9 +47,30 @@ func (a AudioOutput) String() string {
 		"audio-16khz-128kbitrate-mono-mp3",
 		"audio-24khz-48kbitrate-mono-mp3",
 		"audio-24khz-96kbitrate-mono-mp3",
+		"raw-8khz-8bit-mono-alaw",
 	}[a]
 }
 
+// ContentType returns the MIME type of audio encoded in this format.
+func (a AudioOutput) ContentType() string {
+	name := a.String()
+	switch {
+	case strings.HasSuffix(name, "-mp3"):
+		return "audio/mpeg"
+	case strings.HasPrefix(name, "riff-"):
+		return "audio/wav"
+	case strings.HasSuffix(name, "-mulaw"):
+		return "audio/basic"
+	case strings.HasSuffix(name, "-alaw"):
+		return "audio/x-alaw-basic"
+	case strings.HasSuffix(name, "-siren"):
+		return "audio/siren"
+	case strings.HasPrefix(name, "raw-"):
+		return "audio/pcm"
+	}
+	return "application/octet-stream"
+}
+
 func StringToAudioOutput(s string) (AudioOutput, error) {
 	audioMap := map[string]AudioOutput{
 		"riff-8khz-8bit-mono-mulaw":        AudioRIFF8Bit8kHzMonoPCM,
@@ -63,6 +88,7 @@ func StringToAudioOutput(s string) (AudioOutput, error) {
 		"audio-16khz-128kbitrate-mono-mp3": Audio16khz128kbitrateMonoMp3,
 		"audio-24khz-48kbitrate-mono-mp3":  Audio24khz48kbitrateMonoMp3,
 		"audio-24khz-96kbitrate-mono-mp3":  Audio24khz96kbitrateMonoMp3,
+		"raw-8khz-8bit-mono-alaw":          AudioRAW8Bit8kHzMonoAlaw,
 	}
 
 	if audioOutput, exists := audioMap[s]; exists {
@@ -84,122 +110,14 @@ const (
 	GenderFemale               // Female
 )
 
-// Locale references the language or locale for text-to-speech.
-// See "locale" in https://docs.microsoft.com/en-us/azure/cognitive-services/speech-service/language-support#standard-voices
-//
-//go:generate enumer -type=Locale -linecomment -json
-type Locale int
+// DateFormat is the field order of a date read with <say-as interpret-as="date">.
+type DateFormat string
 
 const (
-	LocaleArEG  Locale = 1  // ar-EG
-	LocaleArSA  Locale = 2  // ar-SA
-	LocaleBgBG  Locale = 3  // bg-BG
-	LocaleCaES  Locale = 4  // ca-ES
-	LocaleCsCZ  Locale = 5  // cs-CZ
-	LocaleDaDK  Locale = 6  // da-DK
-	LocaleDeAT  Locale = 7  // de-AT
-	LocaleDeCH  Locale = 8  // de-CH
-	LocaleDeDE  Locale = 9  // de-DE
-	LocaleElGR  Locale = 10 // el-GR
-	LocaleEnAU  Locale = 11 // en-AU
-	LocaleEnCA  Locale = 12 // en-CA
-	LocaleEnGB  Locale = 13 // en-GB
-	LocaleEnIE  Locale = 14 // en-IE
-	LocaleEnIN  Locale = 15 // en-IN
-	LocaleEnUS  Locale = 16 // en-US
-	LocaleEsES  Locale = 17 // es-ES
-	LocaleEsMX  Locale = 18 // es-MX
-	LocaleEtEE  Locale = 19 // et-EE
-	LocaleFiFI  Locale = 20 // fi-FI
-	LocaleFrCA  Locale = 21 // fr-CA
-	LocaleFrCH  Locale = 22 // fr-CH
-	LocaleFrFR  Locale = 23 // fr-FR
-	LocaleGaIE  Locale = 24 // ga-IE
-	LocaleHeIL  Locale = 25 // he-IL
-	LocaleHiIN  Locale = 26 // hi-IN
-	LocaleHrHR  Locale = 27 // hr-HR
-	LocaleHuHU  Locale = 28 // hu-HU
-	LocaleIDID  Locale = 29 // id-ID
-	LocaleItIT  Locale = 30 // it-IT
-	LocaleJaJP  Locale = 31 // ja-JP
-	LocaleKoKR  Locale = 32 // ko-KR
-	LocaleLtLT  Locale = 33 // lt-LT
-	LocaleLvLV  Locale = 34 // lv-LV
-	LocaleMtMT  Locale = 35 // mt-MT
-	LocaleMrIN  Locale = 36 // mr-IN
-	LocaleMsMY  Locale = 37 // ms-MY
-	LocaleNbNO  Locale = 38 // nb-NO
-	LocaleNlNL  Locale = 39 // nl-NL
-	LocalePlPL  Locale = 40 // pl-PL
-	LocalePtBR  Locale = 41 // pt-BR
-	LocalePtPT  Locale = 42 // pt-PT
-	LocaleRoRO  Locale = 43 // ro-RO
-	LocaleRuRU  Locale = 44 // ru-RU
-	LocaleSkSK  Locale = 45 // sk-SK
-	LocaleSlSI  Locale = 46 // sl-SI
-	LocaleSvSE  Locale = 47 // sv-SE
-	LocaleTaIN  Locale = 48 // ta-IN
-	LocaleTeIN  Locale = 49 // te-IN
-	LocaleThTH  Locale = 50 // th-TH
-	LocaleTrTR  Locale = 51 // tr-TR
-	LocaleViVN  Locale = 52 // vi-VN
-	LocaleZhCN  Locale = 53 // zh-CN
-	LocaleZhHK  Locale = 54 // zh-HK
-	LocaleZhTW  Locale = 55 // zh-TW
-	LocaleFilPH Locale = 56 // fil-PH
-	LocaleTaMY  Locale = 57 // ta-MY
-	LocaleKmKH  Locale = 58 // km-KH
+	DateYMD DateFormat = "ymd"
+	DateMDY DateFormat = "mdy"
+	DateDMY DateFormat = "dmy"
+	DateYM  DateFormat = "ym"
+	DateMD  DateFormat = "md"
+	DateDM  DateFormat = "dm"
 )
-
-// Region references the locations of the availability of standard voices.
-// See https://docs.microsoft.com/en-us/azure/cognitive-services/speech-service/regions#standard-voices
-type Region int
-
-const (
-	// Azure regions and their endpoints that support the Text To Speech service.
-	RegionAustraliaEast Region = iota
-	RegionBrazilSouth
-	RegionCanadaCentral
-	RegionCentralUS
-	RegionEastAsia
-	RegionEastUS
-	RegionEastUS2
-	RegionFranceCentral
-	RegionIndiaCentral
-	RegionJapanEast
-	RegionJapanWest
-	RegionKoreaCentral
-	RegionNorthCentralUS
-	RegionNorthEurope
-	RegionSouthCentralUS
-	RegionSoutheastAsia
-	RegionUKSouth
-	RegionWestEurope
-	RegionWestUS
-	RegionWestUS2
-)
-
-func (t Region) String() string {
-	return [...]string{
-		"australiaeast",
-		"brazilsouth",
-		"canadacentral",
-		"centralus",
-		"eastasia",
-		"eastus",
-		"eastus2",
-		"francecentral",
-		"indiacentral",
-		"japaneast",
-		"japanwest",
-		"koreacentral",
-		"northcentralus",
-		"northeurope",
-		"southcentralus",
-		"southeastasia",
-		"uksouth",
-		"westeurope",
-		"westus",
-		"westus2",
-	}[t]
-}
@@ -0,0 +1,367 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProsodyUnit describes how a ProsodyValue is expressed in SSML.
+type ProsodyUnit int
+
+const (
+	ProsodyUnset         ProsodyUnit = iota // attribute is omitted
+	ProsodyNumber                           // rate multiplier (1.2) or absolute volume (0-100)
+	ProsodyPercent                          // relative change, e.g. +10%
+	ProsodySemitones                        // relative pitch, e.g. -2st
+	ProsodyHertz                            // absolute pitch, e.g. 600Hz
+	ProsodyRelativeHertz                    // relative pitch, e.g. +80Hz
+	ProsodyLevel                            // named level, e.g. x-low, high, fast, loud
+)
+
+// ProsodyValue is a single rate, pitch or volume setting. Use the Percent, Semitones, Hertz, etc.
+// constructors or ParseProsodyValue to build one; the zero value leaves the attribute unset.
+type ProsodyValue struct {
+	Unit  ProsodyUnit
+	Value float64
+	Level string
+}
+
+func Number(v float64) ProsodyValue        { return ProsodyValue{Unit: ProsodyNumber, Value: v} }
+func Percent(v float64) ProsodyValue       { return ProsodyValue{Unit: ProsodyPercent, Value: v} }
+func Semitones(v float64) ProsodyValue     { return ProsodyValue{Unit: ProsodySemitones, Value: v} }
+func Hertz(v float64) ProsodyValue         { return ProsodyValue{Unit: ProsodyHertz, Value: v} }
+func RelativeHertz(v float64) ProsodyValue { return ProsodyValue{Unit: ProsodyRelativeHertz, Value: v} }
+func Level(name string) ProsodyValue       { return ProsodyValue{Unit: ProsodyLevel, Level: name} }
+
+// Named levels accepted by the service for each attribute.
+// See: https://learn.microsoft.com/en-us/azure/ai-services/speech-service/speech-synthesis-markup-voice#adjust-prosody
+var (
+	rateLevels   = []string{"x-slow", "slow", "medium", "fast", "x-fast", "default"}
+	pitchLevels  = []string{"x-low", "low", "medium", "high", "x-high", "default"}
+	volumeLevels = []string{"silent", "x-soft", "soft", "medium", "loud", "x-loud", "default"}
+)
+
+// ParseProsodyValue parses the SSML text form of a prosody value: "+10%", "-2st", "600Hz", "+80Hz",
+// a plain number or a named level.
+func ParseProsodyValue(s string) (ProsodyValue, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ProsodyValue{}, nil
+	}
+	if level := strings.ToLower(s); isLevel(level) {
+		return Level(level), nil
+	}
+
+	signed := strings.HasPrefix(s, "+") || strings.HasPrefix(s, "-")
+	unit, number := ProsodyNumber, s
+	switch {
+	case strings.HasSuffix(s, "%"):
+		unit, number = ProsodyPercent, strings.TrimSuffix(s, "%")
+	case strings.HasSuffix(s, "st"):
+		unit, number = ProsodySemitones, strings.TrimSuffix(s, "st")
+	case strings.HasSuffix(strings.ToLower(s), "hz"):
+		unit, number = ProsodyHertz, s[:len(s)-2]
+		if signed {
+			unit = ProsodyRelativeHertz
+		}
+	}
+
+	v, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		if unit == ProsodyNumber && !signed {
+			return Level(strings.ToLower(s)), nil
+		}
+		return ProsodyValue{}, fmt.Errorf("invalid prosody value %q", s)
+	}
+	return ProsodyValue{Unit: unit, Value: v}, nil
+}
+
+// isLevel reports whether s is a named level of rate, pitch or volume.
+func isLevel(s string) bool {
+	for _, levels := range [][]string{rateLevels, pitchLevels, volumeLevels} {
+		for _, level := range levels {
+			if s == level {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsZero reports whether the value is unset.
+func (v ProsodyValue) IsZero() bool {
+	return v.Unit == ProsodyUnset
+}
+
+// String renders the value as an SSML attribute value.
+func (v ProsodyValue) String() string {
+	switch v.Unit {
+	case ProsodyNumber:
+		return formatFloat(v.Value)
+	case ProsodyPercent:
+		return signedFloat(v.Value) + "%"
+	case ProsodySemitones:
+		return signedFloat(v.Value) + "st"
+	case ProsodyHertz:
+		return formatFloat(v.Value) + "Hz"
+	case ProsodyRelativeHertz:
+		return signedFloat(v.Value) + "Hz"
+	case ProsodyLevel:
+		return v.Level
+	}
+	return ""
+}
+
+func (v ProsodyValue) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+func (v *ProsodyValue) UnmarshalText(text []byte) error {
+	parsed, err := ParseProsodyValue(string(text))
+	if err != nil {
+		return err
+	}
+	*v = parsed
+	return nil
+}
+
+// ContourPoint is a pitch target at Position percent (0-100) of the text duration.
+type ContourPoint struct {
+	Position float64
+	Pitch    ProsodyValue
+}
+
+func (c ContourPoint) String() string {
+	return fmt.Sprintf("(%s%%,%s)", formatFloat(c.Position), c.Pitch)
+}
+
+func (c ContourPoint) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText parses the SSML form "(position%,pitch)", e.g. "(60%,-60%)" or "(0%,+20Hz)".
+func (c *ContourPoint) UnmarshalText(text []byte) error {
+	s := strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(string(text)), "("), ")")
+	position, pitch, ok := strings.Cut(s, ",")
+	if !ok {
+		return fmt.Errorf("invalid contour point %q", text)
+	}
+	pos, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(position), "%"), 64)
+	if err != nil {
+		return fmt.Errorf("invalid contour point %q", text)
+	}
+	value, err := ParseProsodyValue(pitch)
+	if err != nil {
+		return err
+	}
+	*c = ContourPoint{Position: pos, Pitch: value}
+	return nil
+}
+
+// Prosody controls how text is spoken. Unset fields are left to the voice defaults.
+type Prosody struct {
+	Rate     ProsodyValue   `json:"rate,omitempty"     yaml:"rate,omitempty"`
+	Pitch    ProsodyValue   `json:"pitch,omitempty"    yaml:"pitch,omitempty"`
+	Volume   ProsodyValue   `json:"volume,omitempty"   yaml:"volume,omitempty"`
+	Contour  []ContourPoint `json:"contour,omitempty"  yaml:"contour,omitempty"`
+	Duration time.Duration  `json:"duration,omitempty" yaml:"duration,omitempty"`
+}
+
+// IsZero reports whether p changes nothing, in which case no <prosody> element is needed.
+func (p *Prosody) IsZero() bool {
+	return p == nil || (p.Rate.IsZero() && p.Pitch.IsZero() && p.Volume.IsZero() && len(p.Contour) == 0 && p.Duration == 0)
+}
+
+// Merge returns p with its unset fields taken from defaults.
+func (p *Prosody) Merge(defaults *Prosody) *Prosody {
+	if defaults == nil {
+		return p
+	}
+	if p == nil {
+		merged := *defaults
+		return &merged
+	}
+	merged := *p
+	if merged.Rate.IsZero() {
+		merged.Rate = defaults.Rate
+	}
+	if merged.Pitch.IsZero() {
+		merged.Pitch = defaults.Pitch
+	}
+	if merged.Volume.IsZero() {
+		merged.Volume = defaults.Volume
+	}
+	if merged.Contour == nil {
+		merged.Contour = defaults.Contour
+	}
+	if merged.Duration == 0 {
+		merged.Duration = defaults.Duration
+	}
+	return &merged
+}
+
+// Validate checks every value against the units and ranges the service accepts.
+func (p *Prosody) Validate() error {
+	if p == nil {
+		return nil
+	}
+	if err := validateRate(p.Rate); err != nil {
+		return err
+	}
+	if err := validatePitch("pitch", p.Pitch); err != nil {
+		return err
+	}
+	if err := validateVolume(p.Volume); err != nil {
+		return err
+	}
+	for _, point := range p.Contour {
+		if point.Position < 0 || point.Position > 100 {
+			return &ProsodyError{Field: "contour", Value: point.String(), Reason: "position must be between 0% and 100%"}
+		}
+		if point.Pitch.Unit == ProsodyLevel || point.Pitch.Unit == ProsodyNumber {
+			return &ProsodyError{Field: "contour", Value: point.String(), Reason: "pitch must be relative or in Hz"}
+		}
+		if err := validatePitch("contour", point.Pitch); err != nil {
+			return err
+		}
+	}
+	if p.Duration < 0 {
+		return &ProsodyError{Field: "duration", Value: p.Duration.String(), Reason: "must not be negative"}
+	}
+	return nil
+}
+
+// Attributes renders the <prosody> attributes of p, each prefixed with a space.
+func (p *Prosody) Attributes() string {
+	if p == nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, attr := range []struct {
+		name  string
+		value ProsodyValue
+	}{{"rate", p.Rate}, {"pitch", p.Pitch}, {"volume", p.Volume}} {
+		if !attr.value.IsZero() {
+			fmt.Fprintf(&b, ` %s="%s"`, attr.name, attr.value)
+		}
+	}
+	if len(p.Contour) > 0 {
+		points := make([]string, 0, len(p.Contour))
+		for _, point := range p.Contour {
+			points = append(points, point.String())
+		}
+		fmt.Fprintf(&b, ` contour="%s"`, strings.Join(points, " "))
+	}
+	if p.Duration > 0 {
+		fmt.Fprintf(&b, ` duration="%dms"`, p.Duration.Milliseconds())
+	}
+	return b.String()
+}
+
+// ProsodyFromLegacy converts the deprecated TextToSpeechRequest Rate and Pitch strings, which are
+// multipliers where "1" is the voice default, into a Prosody. Empty strings leave the value unset.
+func ProsodyFromLegacy(rate, pitch string) (*Prosody, error) {
+	p := &Prosody{}
+	if rate != "" {
+		r, err := strconv.ParseFloat(rate, 64)
+		if err != nil {
+			return nil, &ProsodyError{Field: "rate", Value: rate, Reason: "must be a number"}
+		}
+		if r != 1 {
+			p.Rate = Percent(math.Round((r-1)*10000) / 100)
+		}
+	}
+	if pitch != "" {
+		v, err := strconv.ParseFloat(pitch, 64)
+		if err != nil {
+			return nil, &ProsodyError{Field: "pitch", Value: pitch, Reason: "must be a number"}
+		}
+		if v != 1 {
+			p.Pitch = Percent(math.Round((v-1)*5000) / 100)
+		}
+	}
+	return p, nil
+}
+
+func validateRate(v ProsodyValue) error {
+	switch v.Unit {
+	case ProsodyUnset:
+		return nil
+	case ProsodyNumber:
+		return checkRange("rate", v, 0.5, 2)
+	case ProsodyPercent:
+		return checkRange("rate", v, -50, 100)
+	case ProsodyLevel:
+		return checkLevel("rate", v, rateLevels)
+	}
+	return &ProsodyError{Field: "rate", Value: v.String(), Reason: "must be a multiplier, a percentage or a named level"}
+}
+
+func validatePitch(field string, v ProsodyValue) error {
+	switch v.Unit {
+	case ProsodyUnset:
+		return nil
+	case ProsodyPercent:
+		return checkRange(field, v, -50, 50)
+	case ProsodySemitones:
+		return checkRange(field, v, -12, 12)
+	case ProsodyHertz:
+		if v.Value <= 0 {
+			return &ProsodyError{Field: field, Value: v.String(), Reason: "must be greater than 0Hz"}
+		}
+		return nil
+	case ProsodyRelativeHertz:
+		return nil
+	case ProsodyLevel:
+		return checkLevel(field, v, pitchLevels)
+	}
+	return &ProsodyError{Field: field, Value: v.String(), Reason: "must be relative, in Hz or a named level"}
+}
+
+func validateVolume(v ProsodyValue) error {
+	switch v.Unit {
+	case ProsodyUnset:
+		return nil
+	case ProsodyNumber:
+		return checkRange("volume", v, 0, 100)
+	case ProsodyPercent:
+		return checkRange("volume", v, -100, 100)
+	case ProsodyLevel:
+		return checkLevel("volume", v, volumeLevels)
+	}
+	return &ProsodyError{Field: "volume", Value: v.String(), Reason: "must be 0-100, a percentage or a named level"}
+}
+
+func checkRange(field string, v ProsodyValue, minValue, maxValue float64) error {
+	if v.Value < minValue || v.Value > maxValue {
+		return &ProsodyError{
+			Field:  field,
+			Value:  v.String(),
+			Reason: fmt.Sprintf("must be between %s and %s", ProsodyValue{Unit: v.Unit, Value: minValue}, ProsodyValue{Unit: v.Unit, Value: maxValue}),
+		}
+	}
+	return nil
+}
+
+func checkLevel(field string, v ProsodyValue, levels []string) error {
+	for _, level := range levels {
+		if v.Level == level {
+			return nil
+		}
+	}
+	return &ProsodyError{Field: field, Value: v.Level, Reason: "must be one of " + strings.Join(levels, ", ")}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func signedFloat(v float64) string {
+	if v >= 0 {
+		return "+" + formatFloat(v)
+	}
+	return formatFloat(v)
+}
@@ -0,0 +1,85 @@
+package model_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProsodyValue(t *testing.T) {
+	tests := []struct {
+		input string
+		want  model.ProsodyValue
+	}{
+		{input: "", want: model.ProsodyValue{}},
+		{input: "1.2", want: model.Number(1.2)},
+		{input: "+10%", want: model.Percent(10)},
+		{input: "-2st", want: model.Semitones(-2)},
+		{input: "600Hz", want: model.Hertz(600)},
+		{input: "+80Hz", want: model.RelativeHertz(80)},
+		{input: "x-high", want: model.Level("x-high")},
+		{input: "fast", want: model.Level("fast")},
+		{input: "x-fast", want: model.Level("x-fast")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := model.ParseProsodyValue(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.input, got.String())
+		})
+	}
+
+	_, err := model.ParseProsodyValue("+fast")
+	assert.Error(t, err)
+}
+
+func TestProsodyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		prosody *model.Prosody
+		field   string
+	}{
+		{name: "nil", prosody: nil},
+		{name: "valid", prosody: &model.Prosody{Rate: model.Number(1.5), Pitch: model.Percent(-20), Volume: model.Number(80)}},
+		{name: "rate too fast", prosody: &model.Prosody{Rate: model.Number(3)}, field: "rate"},
+		{name: "unknown rate level", prosody: &model.Prosody{Rate: model.Level("x-low")}, field: "rate"},
+		{name: "pitch in semitones", prosody: &model.Prosody{Pitch: model.Semitones(13)}, field: "pitch"},
+		{name: "volume out of range", prosody: &model.Prosody{Volume: model.Number(120)}, field: "volume"},
+		{name: "contour position", prosody: &model.Prosody{Contour: []model.ContourPoint{{Position: 120, Pitch: model.Percent(1)}}}, field: "contour"},
+		{name: "contour level", prosody: &model.Prosody{Contour: []model.ContourPoint{{Position: 10, Pitch: model.Level("high")}}}, field: "contour"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.prosody.Validate()
+			if tt.field == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var prosodyErr *model.ProsodyError
+			assert.True(t, errors.As(err, &prosodyErr))
+			assert.Equal(t, tt.field, prosodyErr.Field)
+		})
+	}
+}
+
+func TestProsodyFromLegacy(t *testing.T) {
+	p, err := model.ProsodyFromLegacy("1.15", "1")
+	assert.NoError(t, err)
+	assert.Equal(t, &model.Prosody{Rate: model.Percent(15)}, p)
+
+	p, err = model.ProsodyFromLegacy("", "0.8")
+	assert.NoError(t, err)
+	assert.Equal(t, &model.Prosody{Pitch: model.Percent(-10)}, p)
+
+	p, err = model.ProsodyFromLegacy("1", "1")
+	assert.NoError(t, err)
+	assert.True(t, p.IsZero())
+
+	_, err = model.ProsodyFromLegacy("fast", "1")
+	assert.Error(t, err)
+}
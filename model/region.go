@@ -0,0 +1,59 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Region is the name of an Azure region hosting the Speech service, such as "eastasia". Any
+// well-formed name is accepted, so new regions work without a library release; the constants below
+// are well-known values.
+// See https://learn.microsoft.com/en-us/azure/ai-services/speech-service/regions
+type Region string
+
+const (
+	// Azure regions and their endpoints that support the Text To Speech service.
+	RegionAustraliaEast      Region = "australiaeast"
+	RegionBrazilSouth        Region = "brazilsouth"
+	RegionCanadaCentral      Region = "canadacentral"
+	RegionCentralUS          Region = "centralus"
+	RegionEastAsia           Region = "eastasia"
+	RegionEastUS             Region = "eastus"
+	RegionEastUS2            Region = "eastus2"
+	RegionFranceCentral      Region = "francecentral"
+	RegionIndiaCentral       Region = "indiacentral"
+	RegionJapanEast          Region = "japaneast"
+	RegionJapanWest          Region = "japanwest"
+	RegionKoreaCentral       Region = "koreacentral"
+	RegionNorthCentralUS     Region = "northcentralus"
+	RegionNorthEurope        Region = "northeurope"
+	RegionSouthCentralUS     Region = "southcentralus"
+	RegionSoutheastAsia      Region = "southeastasia"
+	RegionUKSouth            Region = "uksouth"
+	RegionWestEurope         Region = "westeurope"
+	RegionWestUS             Region = "westus"
+	RegionWestUS2            Region = "westus2"
+	RegionGermanyWestCentral Region = "germanywestcentral"
+	RegionNorwayEast         Region = "norwayeast"
+	RegionQatarCentral       Region = "qatarcentral"
+	RegionSouthAfricaNorth   Region = "southafricanorth"
+	RegionSwedenCentral      Region = "swedencentral"
+	RegionSwitzerlandNorth   Region = "switzerlandnorth"
+	RegionUAENorth           Region = "uaenorth"
+	RegionWestCentralUS      Region = "westcentralus"
+	RegionWestUS3            Region = "westus3"
+)
+
+func (r Region) String() string {
+	return string(r)
+}
+
+// ParseRegion returns the Region for an Azure region name such as "eastasia" or "SwedenCentral".
+// Names are made of letters and digits and are returned in lower case.
+func ParseRegion(s string) (Region, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if !isAlphanumeric(name) || len(name) > 40 || isDigits(name[:1]) {
+		return "", fmt.Errorf("invalid region: %q", s)
+	}
+	return Region(name), nil
+}
@@ -5,20 +5,37 @@ import (
 )
 
 type TTSStyle struct {
-	Style       string `json:"style"`       // cheerful, friendly, chat, etc.
-	StyleDegree string `json:"style_degree"` // 1-2
+	Style       string `json:"style"`          // cheerful, friendly, chat, etc.
+	StyleDegree string `json:"style_degree"`   // 1-2
+	Role        string `json:"role,omitempty"` // YoungAdultFemale, OlderAdultMale, etc.
 }
 
 type TextToSpeechRequest struct {
-	SpeechText  string
-	Locale      Locale
-	Gender      Gender
+	SpeechText string
+	Locale     Locale
+	Gender     Gender
+	// VoiceName is the short name of the voice. When empty, a voice for Locale is selected from the
+	// voice catalog, see VoicePreferences.
 	VoiceName   string
 	AudioOutput AudioOutput
-	Rate        string
-	Pitch       string
-	Homophones  []Homophones
-	Style       *TTSStyle // 新增風格選項
+	// Deprecated: Rate and Pitch are multipliers kept for compatibility, use Prosody instead.
+	Rate       string
+	Pitch      string
+	Prosody    *Prosody
+	Homophones []Homophones
+	Style      *TTSStyle // 新增風格選項
+	// InlineMarkup enables [[tag:args|text]] markup in SpeechText, e.g. "[[break:500ms]]" or
+	// "[[say-as:digits|1234]]". Plain text is XML-escaped in this mode; a backslash escapes the next character.
+	InlineMarkup bool
+	// StyleProfile is resolved against the voice catalog at synthesis time when Style is nil.
+	StyleProfile *StyleProfile
+	// VoicePreferences guides the voice selection when VoiceName is empty. When nil, voices of any
+	// gender that support the speaking style of Style are preferred. Gender is ignored by the selection
+	// and replaced by the gender of the selected voice; set VoicePreferences.Gender to prefer one.
+	VoicePreferences *VoicePreferences
+	// Languages enables speaking the parts of SpeechText written in other scripts, e.g. English terms in
+	// Chinese text, in their own language. See LanguageDetection.
+	Languages *LanguageDetection
 }
 
 type Homophones struct {
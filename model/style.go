@@ -0,0 +1,75 @@
+package model
+
+// StyleProfile is a named speaking style that can be applied to any voice. Style is tried first,
+// then each of Fallbacks in order; the first one the target voice supports is used. When none is
+// supported the profile resolves to no style at all.
+type StyleProfile struct {
+	Name        string   `json:"name"         yaml:"name"`
+	Description string   `json:"description"  yaml:"description"`
+	Style       string   `json:"style"        yaml:"style"`
+	StyleDegree string   `json:"style_degree" yaml:"style_degree"`
+	Role        string   `json:"role"         yaml:"role"`
+	Fallbacks   []string `json:"fallbacks"    yaml:"fallbacks"`
+	// Prosody holds defaults for the values the request leaves unset.
+	Prosody *Prosody `json:"prosody" yaml:"prosody"`
+	// Voices overrides the profile for specific voices, keyed by the voice short name.
+	Voices map[string]StyleProfileVoice `json:"voices" yaml:"voices"`
+}
+
+// StyleProfileVoice overrides parts of a StyleProfile for a single voice. Empty fields keep the profile value.
+type StyleProfileVoice struct {
+	Style       string   `json:"style"        yaml:"style"`
+	StyleDegree string   `json:"style_degree" yaml:"style_degree"`
+	Role        string   `json:"role"         yaml:"role"`
+	Fallbacks   []string `json:"fallbacks"    yaml:"fallbacks"`
+}
+
+// ForVoice returns the profile with the overrides registered for voiceName applied.
+func (p StyleProfile) ForVoice(voiceName string) StyleProfile {
+	override, ok := p.Voices[voiceName]
+	if !ok {
+		return p
+	}
+	if override.Style != "" {
+		p.Style = override.Style
+	}
+	if override.StyleDegree != "" {
+		p.StyleDegree = override.StyleDegree
+	}
+	if override.Role != "" {
+		p.Role = override.Role
+	}
+	if override.Fallbacks != nil {
+		p.Fallbacks = override.Fallbacks
+	}
+	return p
+}
+
+// Resolve picks the style to use for voice. A nil voice means the catalog is unknown, in which
+// case the primary style and the role are used as is; otherwise the role is dropped when the voice
+// doesn't support it. It returns nil when neither a style nor a role applies.
+func (p StyleProfile) Resolve(voice *VoiceListResponse) *TTSStyle {
+	if voice != nil {
+		p = p.ForVoice(voice.ShortName)
+	}
+
+	style := &TTSStyle{}
+	if voice == nil || voice.SupportsRole(p.Role) {
+		style.Role = p.Role
+	}
+	for _, candidate := range append([]string{p.Style}, p.Fallbacks...) {
+		if candidate == "" {
+			continue
+		}
+		if voice == nil || voice.SupportsStyle(candidate) {
+			style.Style = candidate
+			style.StyleDegree = p.StyleDegree
+			break
+		}
+	}
+
+	if style.Style == "" && style.Role == "" {
+		return nil
+	}
+	return style
+}
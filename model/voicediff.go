@@ -0,0 +1,133 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// VoiceChangeKind is the kind of a change between two voice lists.
+type VoiceChangeKind int
+
+const (
+	// VoiceAdded is a voice that wasn't listed before.
+	VoiceAdded VoiceChangeKind = iota
+	// VoiceRemoved is a voice that is no longer listed. Requests for it will fail.
+	VoiceRemoved
+	// VoiceStylesAdded lists new speaking styles of a voice.
+	VoiceStylesAdded
+	// VoiceStylesRemoved lists speaking styles a voice no longer supports.
+	VoiceStylesRemoved
+	// VoiceStatusChanged is a change of Status, e.g. from Preview to GA.
+	VoiceStatusChanged
+)
+
+func (k VoiceChangeKind) String() string {
+	switch k {
+	case VoiceAdded:
+		return "added"
+	case VoiceRemoved:
+		return "removed"
+	case VoiceStylesAdded:
+		return "styles added"
+	case VoiceStylesRemoved:
+		return "styles removed"
+	case VoiceStatusChanged:
+		return "status changed"
+	}
+	return fmt.Sprintf("VoiceChangeKind(%d)", int(k))
+}
+
+// VoiceChange is a change of one voice between two voice lists.
+type VoiceChange struct {
+	Kind      VoiceChangeKind
+	ShortName string
+	// Voice is the voice in the newer list, or in the older one for VoiceRemoved.
+	Voice VoiceListResponse
+	// Styles are the added or removed speaking styles.
+	Styles []string
+	// OldStatus and NewStatus are set for VoiceStatusChanged.
+	OldStatus string
+	NewStatus string
+}
+
+func (c VoiceChange) String() string {
+	switch c.Kind {
+	case VoiceStylesAdded, VoiceStylesRemoved:
+		return fmt.Sprintf("%s: %s %s", c.ShortName, c.Kind, strings.Join(c.Styles, ","))
+	case VoiceStatusChanged:
+		return fmt.Sprintf("%s: status %s -> %s", c.ShortName, c.OldStatus, c.NewStatus)
+	}
+	return fmt.Sprintf("%s: %s", c.ShortName, c.Kind)
+}
+
+// DiffVoices returns the changes from the voice list older to newer, ordered by ShortName and kind.
+// Voices are matched by ShortName.
+func DiffVoices(older, newer []VoiceListResponse) []VoiceChange {
+	before := make(map[string]*VoiceListResponse, len(older))
+	for i := range older {
+		before[older[i].ShortName] = &older[i]
+	}
+	after := make(map[string]*VoiceListResponse, len(newer))
+	for i := range newer {
+		after[newer[i].ShortName] = &newer[i]
+	}
+
+	var changes []VoiceChange
+	for name, o := range before {
+		if _, ok := after[name]; !ok {
+			changes = append(changes, VoiceChange{Kind: VoiceRemoved, ShortName: name, Voice: *o})
+		}
+	}
+	for name, n := range after {
+		o, ok := before[name]
+		if !ok {
+			changes = append(changes, VoiceChange{Kind: VoiceAdded, ShortName: name, Voice: *n})
+			continue
+		}
+		if added := missingStyles(n.StyleList, o); len(added) > 0 {
+			changes = append(changes, VoiceChange{Kind: VoiceStylesAdded, ShortName: name, Voice: *n, Styles: added})
+		}
+		if removed := missingStyles(o.StyleList, n); len(removed) > 0 {
+			changes = append(changes, VoiceChange{Kind: VoiceStylesRemoved, ShortName: name, Voice: *n, Styles: removed})
+		}
+		if o.Status != n.Status {
+			changes = append(changes, VoiceChange{
+				Kind: VoiceStatusChanged, ShortName: name, Voice: *n, OldStatus: o.Status, NewStatus: n.Status,
+			})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].ShortName != changes[j].ShortName {
+			return changes[i].ShortName < changes[j].ShortName
+		}
+		return changes[i].Kind < changes[j].Kind
+	})
+	return changes
+}
+
+// DiffVoiceSnapshots returns the changes between two voice lists saved as JSON, e.g. with
+// "azuretts voices -json".
+func DiffVoiceSnapshots(older, newer []byte) ([]VoiceChange, error) {
+	var before, after []VoiceListResponse
+	if err := json.Unmarshal(older, &before); err != nil {
+		return nil, fmt.Errorf("parsing old voice list: %w", err)
+	}
+	if err := json.Unmarshal(newer, &after); err != nil {
+		return nil, fmt.Errorf("parsing new voice list: %w", err)
+	}
+	return DiffVoices(before, after), nil
+}
+
+// missingStyles returns the styles that v doesn't support.
+func missingStyles(styles []string, v *VoiceListResponse) []string {
+	var missing []string
+	for _, s := range styles {
+		if !v.SupportsStyle(s) {
+			missing = append(missing, s)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,40 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffVoiceSnapshots(t *testing.T) {
+	older := `[
+		{"ShortName": "zh-TW-HsiaoChenNeural", "VoiceType": "Neural", "StyleList": ["cheerful"], "Status": "GA"},
+		{"ShortName": "zh-TW-YunJheNeural", "VoiceType": "Neural", "Status": "Preview"},
+		{"ShortName": "zh-TW-Retired", "VoiceType": "Standard", "Status": "GA"}
+	]`
+	newer := `[
+		{"ShortName": "zh-TW-HsiaoChenNeural", "VoiceType": "Neural", "StyleList": ["Cheerful", "sad"], "Status": "GA"},
+		{"ShortName": "zh-TW-YunJheNeural", "VoiceType": "Neural", "StyleList": ["calm"], "Status": "GA"},
+		{"ShortName": "zh-TW-HsiaoYuNeural", "VoiceType": "Neural", "Status": "Preview"}
+	]`
+
+	changes, err := model.DiffVoiceSnapshots([]byte(older), []byte(newer))
+	assert.NoError(t, err)
+	got := make([]string, 0, len(changes))
+	for _, c := range changes {
+		got = append(got, c.String())
+	}
+	assert.Equal(t, []string{
+		"zh-TW-HsiaoChenNeural: styles added sad",
+		"zh-TW-HsiaoYuNeural: added",
+		"zh-TW-Retired: removed",
+		"zh-TW-YunJheNeural: styles added calm",
+		"zh-TW-YunJheNeural: status Preview -> GA",
+	}, got)
+	assert.Equal(t, "zh-TW-Retired", changes[2].Voice.ShortName, "removed voices keep their old entry")
+
+	assert.Empty(t, model.DiffVoices(nil, nil))
+	_, err = model.DiffVoiceSnapshots([]byte(older), []byte("not json"))
+	assert.Error(t, err)
+}
@@ -1,17 +1,77 @@
 package model
 
-type voiceType int
+import "strings"
 
+// VoiceType is the kind of a voice as reported by the voice list. Kinds not listed here, which Azure
+// adds from time to time, are kept as they are.
+type VoiceType string
+
+const (
+	VoiceTypeStandard VoiceType = "Standard"
+	VoiceTypeNeural   VoiceType = "Neural"
+	VoiceTypeNeuralHD VoiceType = "NeuralHD"
+)
+
+// IsNeural reports whether the voice is a neural voice of any kind.
+func (t VoiceType) IsNeural() bool {
+	return strings.HasPrefix(string(t), string(VoiceTypeNeural))
+}
+
+// VoiceTag describes what a voice is suited for.
+type VoiceTag struct {
+	TailoredScenarios  []string `json:"TailoredScenarios,omitempty"`
+	VoicePersonalities []string `json:"VoicePersonalities,omitempty"`
+}
+
+// VoiceListResponse is a voice of the voices/list endpoint.
+// See: https://learn.microsoft.com/en-us/azure/ai-services/speech-service/rest-text-to-speech#get-a-list-of-voices
 type VoiceListResponse struct {
-	Name            string    `json:"Name"`
-	LocalName       string    `json:"LocalName"`
-	ShortName       string    `json:"ShortName"`
-	Gender          string    `json:"Gender"`
-	Locale          string    `json:"Locale"`
-	LocaleName      string    `json:"LocaleName"`
-	SampleRateHertz string    `json:"SampleRateHertz"`
-	VoiceType       voiceType `json:"VoiceType"`
-	StyleList       []string  `json:"StyleList"`
-	Status          string    `json:"Status"`
-	WordsPerMinute  string    `json:"WordsPerMinute"`
+	Name        string `json:"Name"`
+	DisplayName string `json:"DisplayName,omitempty"`
+	LocalName   string `json:"LocalName"`
+	ShortName   string `json:"ShortName"`
+	Gender      string `json:"Gender"`
+	Locale      string `json:"Locale"`
+	LocaleName  string `json:"LocaleName"`
+	// SecondaryLocaleList holds the other locales a multilingual voice speaks.
+	SecondaryLocaleList []string  `json:"SecondaryLocaleList,omitempty"`
+	SampleRateHertz     string    `json:"SampleRateHertz"`
+	VoiceType           VoiceType `json:"VoiceType"`
+	StyleList           []string  `json:"StyleList"`
+	// RolePlayList holds the roles the voice can imitate, e.g. YoungAdultFemale.
+	RolePlayList []string `json:"RolePlayList,omitempty"`
+	Status       string   `json:"Status"`
+	// ExtendedPropertyMap holds additional properties, e.g. IsHighQuality48K.
+	ExtendedPropertyMap map[string]any `json:"ExtendedPropertyMap,omitempty"`
+	WordsPerMinute      string         `json:"WordsPerMinute"`
+	VoiceTag            *VoiceTag      `json:"VoiceTag,omitempty"`
+}
+
+// SupportsStyle reports whether the voice lists style among its speaking styles.
+func (v *VoiceListResponse) SupportsStyle(style string) bool {
+	return containsFold(v.StyleList, style)
+}
+
+// SupportsRole reports whether the voice lists role among its role-play roles.
+func (v *VoiceListResponse) SupportsRole(role string) bool {
+	return containsFold(v.RolePlayList, role)
+}
+
+// SupportsLocale reports whether the voice speaks locale, as its primary or a secondary locale.
+func (v *VoiceListResponse) SupportsLocale(locale string) bool {
+	return strings.EqualFold(v.Locale, locale) || containsFold(v.SecondaryLocaleList, locale)
+}
+
+// IsMultilingual reports whether the voice speaks more than its primary locale.
+func (v *VoiceListResponse) IsMultilingual() bool {
+	return len(v.SecondaryLocaleList) > 0 || strings.Contains(v.ShortName, "Multilingual")
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,74 @@
+package model_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVoiceListResponseDecoding(t *testing.T) {
+	data := `[{
+		"Name": "Microsoft Server Speech Text to Speech Voice (zh-CN, XiaoxiaoMultilingualNeural)",
+		"DisplayName": "Xiaoxiao Multilingual",
+		"LocalName": "晓晓 多语言",
+		"ShortName": "zh-CN-XiaoxiaoMultilingualNeural",
+		"Gender": "Female",
+		"Locale": "zh-CN",
+		"LocaleName": "Chinese (Mandarin, Simplified)",
+		"SecondaryLocaleList": ["en-US", "ja-JP"],
+		"StyleList": ["cheerful", "sad"],
+		"RolePlayList": ["YoungAdultFemale", "OlderAdultMale"],
+		"SampleRateHertz": "24000",
+		"VoiceType": "NeuralHD",
+		"Status": "Preview",
+		"ExtendedPropertyMap": {"IsHighQuality48K": "True"},
+		"VoiceTag": {"TailoredScenarios": ["Chat"], "VoicePersonalities": ["Warm", "Bright"]},
+		"WordsPerMinute": "200"
+	}, {
+		"ShortName": "zh-TW-HanHanRUS",
+		"Locale": "zh-TW",
+		"VoiceType": "Standard"
+	}]`
+
+	var voices []model.VoiceListResponse
+	require.NoError(t, json.Unmarshal([]byte(data), &voices))
+
+	v := voices[0]
+	assert.Equal(t, model.VoiceTypeNeuralHD, v.VoiceType)
+	assert.True(t, v.VoiceType.IsNeural())
+	assert.Equal(t, "Xiaoxiao Multilingual", v.DisplayName)
+	assert.True(t, v.IsMultilingual())
+	assert.True(t, v.SupportsLocale("ja-jp"))
+	assert.True(t, v.SupportsRole("olderadultmale"))
+	assert.True(t, v.SupportsStyle("Cheerful"))
+	assert.Equal(t, "True", v.ExtendedPropertyMap["IsHighQuality48K"])
+	assert.Equal(t, []string{"Warm", "Bright"}, v.VoiceTag.VoicePersonalities)
+
+	standard := voices[1]
+	assert.False(t, standard.VoiceType.IsNeural())
+	assert.False(t, standard.IsMultilingual())
+	assert.False(t, standard.SupportsRole("YoungAdultFemale"))
+	assert.Nil(t, standard.VoiceTag)
+
+	// unknown voice types survive a round trip
+	var custom model.VoiceListResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"VoiceType": "NeuralFlash"}`), &custom))
+	out, err := json.Marshal(custom)
+	require.NoError(t, err)
+	assert.Contains(t, string(out), `"VoiceType":"NeuralFlash"`)
+}
+
+func TestStyleProfileResolveRole(t *testing.T) {
+	profile := model.StyleProfile{Style: "cheerful", Role: "YoungAdultFemale"}
+
+	withRoles := &model.VoiceListResponse{StyleList: []string{"cheerful"}, RolePlayList: []string{"YoungAdultFemale"}}
+	assert.Equal(t, &model.TTSStyle{Style: "cheerful", Role: "YoungAdultFemale"}, profile.Resolve(withRoles))
+
+	withoutRoles := &model.VoiceListResponse{StyleList: []string{"cheerful"}}
+	assert.Equal(t, &model.TTSStyle{Style: "cheerful"}, profile.Resolve(withoutRoles))
+
+	assert.Equal(t, &model.TTSStyle{Style: "cheerful", Role: "YoungAdultFemale"}, profile.Resolve(nil))
+}
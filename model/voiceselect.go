@@ -0,0 +1,150 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// localeFallbacks lists the locales tried for a language, a language and script or a language and
+// region, most suitable first. Chinese falls back along the script first, then to the other regions.
+var localeFallbacks = map[Locale][]Locale{
+	"zh":      {LocaleZhCN, LocaleZhTW, LocaleZhHK},
+	"zh-Hans": {LocaleZhCN, "zh-SG", LocaleZhTW, LocaleZhHK},
+	"zh-Hant": {LocaleZhTW, LocaleZhHK, LocaleZhCN},
+	"zh-TW":   {LocaleZhTW, LocaleZhHK, LocaleZhCN},
+	"zh-HK":   {LocaleZhHK, LocaleYueCN, LocaleZhTW, LocaleZhCN},
+	"zh-MO":   {LocaleZhHK, LocaleZhTW, LocaleZhCN},
+	"zh-SG":   {"zh-SG", LocaleZhCN, LocaleZhTW},
+	"yue":     {LocaleYueCN, LocaleZhHK},
+	"wuu":     {LocaleWuuCN, LocaleZhCN},
+	"ar":      {LocaleArSA, LocaleArEG},
+	"de":      {LocaleDeDE, LocaleDeAT, LocaleDeCH},
+	"en":      {LocaleEnUS, LocaleEnGB, LocaleEnAU, LocaleEnCA},
+	"es":      {LocaleEsES, LocaleEsMX},
+	"fr":      {LocaleFrFR, LocaleFrCA, LocaleFrCH},
+	"pt":      {LocalePtBR, LocalePtPT},
+	"ta":      {LocaleTaIN, LocaleTaMY},
+}
+
+// VoicePreferences guides the choice of a voice for a language.
+type VoicePreferences struct {
+	// Gender is "Male" or "Female". Voices of the gender are preferred; any gender when empty.
+	Gender string `json:"gender"      yaml:"gender"`
+	// Style is a speaking style the voice must support, e.g. cheerful.
+	Style string `json:"style"       yaml:"style"`
+	// NeuralOnly excludes standard voices.
+	NeuralOnly bool `json:"neural_only" yaml:"neural_only"`
+	// Voices are preferred voices by short name, most preferred first. They win over the other
+	// voices of the locale they are chosen for.
+	Voices []string `json:"voices"      yaml:"voices"`
+}
+
+// LocaleFallbacks returns the locales tried for locale, most suitable first, e.g. zh-TW, zh-HK and
+// zh-CN for zh-Hant. Variants are kept for the first entry only, so zh-CN-sichuan falls back to zh-CN.
+func LocaleFallbacks(locale Locale) []Locale {
+	var chain []Locale
+	seen := map[Locale]bool{}
+	add := func(locales ...Locale) {
+		for _, l := range locales {
+			if !seen[l] {
+				seen[l] = true
+				chain = append(chain, l)
+			}
+		}
+	}
+
+	language, script, region := locale.Language(), locale.Script(), locale.Region()
+	if region != "" {
+		add(locale)
+		if script != "" {
+			add(Locale(language + "-" + script + "-" + region))
+		}
+		add(Locale(language + "-" + region))
+		add(localeFallbacks[Locale(language+"-"+region)]...)
+	}
+	if script != "" {
+		add(localeFallbacks[Locale(language+"-"+script)]...)
+	}
+	add(localeFallbacks[Locale(language)]...)
+	return chain
+}
+
+// SelectVoice returns the best voice of voices for the language tag, e.g. "zh-Hant" or "en", and the
+// locale it speaks the text in. The locales of LocaleFallbacks are tried in order, then the other
+// locales of the same language. Within a locale, preferred voices win, then voices with the locale as
+// their primary locale, voices of the preferred gender, neural voices, and generally available voices
+// over previews. It returns an error matching ErrNoVoice when no voice qualifies.
+func SelectVoice(voices []VoiceListResponse, tag string, prefs VoicePreferences) (*VoiceListResponse, Locale, error) {
+	locale, err := ParseLocale(tag)
+	if err != nil {
+		return nil, "", err
+	}
+
+	chain := LocaleFallbacks(locale)
+	for _, l := range LocalesFromVoices(voices) {
+		if l.Language() == locale.Language() {
+			chain = append(chain, l)
+		}
+	}
+	for _, l := range chain {
+		if voice := bestVoice(voices, l, prefs); voice != nil {
+			return voice, l, nil
+		}
+	}
+	return nil, "", fmt.Errorf("%w for %s", ErrNoVoice, tag)
+}
+
+// bestVoice returns the highest ranked voice speaking locale that meets the requirements of prefs,
+// or nil if there is none.
+func bestVoice(voices []VoiceListResponse, locale Locale, prefs VoicePreferences) *VoiceListResponse {
+	var candidates []*VoiceListResponse
+	for i := range voices {
+		v := &voices[i]
+		if !v.SupportsLocale(string(locale)) ||
+			prefs.NeuralOnly && !v.VoiceType.IsNeural() ||
+			prefs.Style != "" && !v.SupportsStyle(prefs.Style) {
+			continue
+		}
+		candidates = append(candidates, v)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	preferred := func(v *VoiceListResponse) int {
+		for i, name := range prefs.Voices {
+			if strings.EqualFold(name, v.ShortName) {
+				return i
+			}
+		}
+		return len(prefs.Voices)
+	}
+	rank := func(v *VoiceListResponse) []int {
+		return []int{
+			preferred(v),
+			boolRank(strings.EqualFold(v.Locale, string(locale))),
+			boolRank(prefs.Gender == "" || strings.EqualFold(v.Gender, prefs.Gender)),
+			boolRank(v.VoiceType.IsNeural()),
+			boolRank(!strings.EqualFold(v.Status, "Preview")),
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		ri, rj := rank(candidates[i]), rank(candidates[j])
+		for k := range ri {
+			if ri[k] != rj[k] {
+				return ri[k] < rj[k]
+			}
+		}
+		return candidates[i].ShortName < candidates[j].ShortName
+	})
+	return candidates[0]
+}
+
+// boolRank ranks true before false.
+func boolRank(b bool) int {
+	if b {
+		return 0
+	}
+	return 1
+}
@@ -0,0 +1,95 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocaleFallbacks(t *testing.T) {
+	tests := []struct {
+		locale model.Locale
+		want   []model.Locale
+	}{
+		{locale: "zh-Hant", want: []model.Locale{"zh-TW", "zh-HK", "zh-CN"}},
+		{locale: "zh-Hant-HK", want: []model.Locale{"zh-Hant-HK", "zh-HK", "yue-CN", "zh-TW", "zh-CN"}},
+		{locale: "zh-CN-sichuan", want: []model.Locale{"zh-CN-sichuan", "zh-CN", "zh-TW", "zh-HK"}},
+		{locale: "en", want: []model.Locale{"en-US", "en-GB", "en-AU", "en-CA"}},
+		{locale: "fr-BE", want: []model.Locale{"fr-BE", "fr-FR", "fr-CA", "fr-CH"}},
+		{locale: "km", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale.String(), func(t *testing.T) {
+			assert.Equal(t, tt.want, model.LocaleFallbacks(tt.locale))
+		})
+	}
+}
+
+func TestSelectVoice(t *testing.T) {
+	voices := []model.VoiceListResponse{
+		{ShortName: "zh-CN-XiaoxiaoNeural", Locale: "zh-CN", Gender: "Female", VoiceType: "Neural", StyleList: []string{"cheerful"}},
+		{ShortName: "zh-CN-YunxiNeural", Locale: "zh-CN", Gender: "Male", VoiceType: "Neural", StyleList: []string{"cheerful"}},
+		{ShortName: "zh-HK-HiuMaanNeural", Locale: "zh-HK", Gender: "Female", VoiceType: "Neural"},
+		{ShortName: "zh-TW-HanHanRUS", Locale: "zh-TW", Gender: "Female", VoiceType: "Standard"},
+		{ShortName: "zh-TW-HsiaoChenNeural", Locale: "zh-TW", Gender: "Female", VoiceType: "Neural", Status: "Preview"},
+		{ShortName: "zh-TW-HsiaoYuNeural", Locale: "zh-TW", Gender: "Female", VoiceType: "Neural", Status: "GA"},
+		{ShortName: "zh-TW-YunJheNeural", Locale: "zh-TW", Gender: "Male", VoiceType: "Neural", Status: "GA"},
+		{
+			ShortName: "en-US-AvaMultilingualNeural", Locale: "en-US", Gender: "Female", VoiceType: "Neural",
+			SecondaryLocaleList: []string{"km-KH"},
+		},
+		{ShortName: "es-MX-DaliaNeural", Locale: "es-MX", Gender: "Female", VoiceType: "Neural"},
+	}
+
+	tests := []struct {
+		name       string
+		tag        string
+		prefs      model.VoicePreferences
+		wantVoice  string
+		wantLocale model.Locale
+		wantErr    error
+	}{
+		{
+			name: "generally available voice of the gender", tag: "zh-Hant", prefs: model.VoicePreferences{Gender: "Female"},
+			wantVoice: "zh-TW-HsiaoYuNeural", wantLocale: "zh-TW",
+		},
+		{
+			name: "gender", tag: "zh_tw", prefs: model.VoicePreferences{Gender: "male"},
+			wantVoice: "zh-TW-YunJheNeural", wantLocale: "zh-TW",
+		},
+		{
+			name: "preferred voice", tag: "zh-TW", prefs: model.VoicePreferences{Gender: "Male", Voices: []string{"x", "zh-TW-HanHanRUS"}},
+			wantVoice: "zh-TW-HanHanRUS", wantLocale: "zh-TW",
+		},
+		{
+			name: "neural only skips the preferred standard voice", tag: "zh-TW",
+			prefs:     model.VoicePreferences{NeuralOnly: true, Voices: []string{"zh-TW-HanHanRUS"}},
+			wantVoice: "zh-TW-HsiaoYuNeural", wantLocale: "zh-TW",
+		},
+		{
+			name: "style falls back along the chain", tag: "zh-Hant", prefs: model.VoicePreferences{Style: "Cheerful", Gender: "Male"},
+			wantVoice: "zh-CN-YunxiNeural", wantLocale: "zh-CN",
+		},
+		{name: "secondary locale", tag: "km", wantVoice: "en-US-AvaMultilingualNeural", wantLocale: "km-KH"},
+		{name: "other region of the language", tag: "es-AR", wantVoice: "es-MX-DaliaNeural", wantLocale: "es-MX"},
+		{name: "no voice", tag: "ja", wantErr: model.ErrNoVoice},
+		{name: "no voice with the style", tag: "es", prefs: model.VoicePreferences{Style: "cheerful"}, wantErr: model.ErrNoVoice},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			voice, locale, err := model.SelectVoice(voices, tt.tag, tt.prefs)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantVoice, voice.ShortName)
+			assert.Equal(t, tt.wantLocale, locale)
+		})
+	}
+
+	_, _, err := model.SelectVoice(voices, "not a tag", model.VoicePreferences{})
+	assert.Error(t, err)
+}
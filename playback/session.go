@@ -0,0 +1,311 @@
+// Package playback plays the synthesized utterances of a conversation one after another and stops
+// them when the caller barges in:
+//
+//	session := playback.NewSession(client, player)
+//	session.Say(&model.TextToSpeechRequest{SpeechText: "您好，請問有什麼可以幫您？"})
+//	session.Say(&model.TextToSpeechRequest{SpeechText: "您可以說出您的訂單編號。"})
+//	...
+//	for _, r := range session.Cancel() { // the caller started speaking
+//		log.Printf("utterance %d: %d of %d bytes played", r.ID, r.Delivered, r.Received)
+//	}
+//
+// The next utterance is synthesized while the current one plays, so there is no gap between them.
+package playback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+)
+
+// ErrClosed is returned by Say once the session was closed.
+var ErrClosed = errors.New("playback: session closed")
+
+// Synthesizer is implemented by api.AzureTTSClient.
+type Synthesizer interface {
+	TextToSpeechStream(ctx context.Context, request *model.TextToSpeechRequest) (io.ReadCloser, error)
+}
+
+// Status is how an utterance ended.
+type Status int
+
+const (
+	// StatusCompleted means all audio of the utterance was written to the player.
+	StatusCompleted Status = iota
+	// StatusCanceled means the utterance was stopped by Cancel, Flush or Close.
+	StatusCanceled
+	// StatusFailed means synthesis or the player failed, see Report.Err.
+	StatusFailed
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusCompleted:
+		return "completed"
+	case StatusCanceled:
+		return "canceled"
+	case StatusFailed:
+		return "failed"
+	}
+	return fmt.Sprintf("Status(%d)", int(s))
+}
+
+// Report describes how much of an utterance was played.
+type Report struct {
+	ID      int
+	Request *model.TextToSpeechRequest
+	Status  Status
+	// Received is the number of audio bytes received from the service.
+	Received int64
+	// Delivered is the number of audio bytes written to the player.
+	Delivered int64
+	Err       error
+}
+
+// Option configures a Session.
+type Option func(*Session)
+
+// WithPrefetch sets how many utterances after the current one are synthesized ahead, 1 by default.
+func WithPrefetch(n int) Option {
+	return func(s *Session) {
+		if n >= 0 {
+			s.prefetch = n
+		}
+	}
+}
+
+// WithChunkSize sets the size of the writes to the player, 1024 bytes by default. Cancel takes
+// effect after the write in progress, so real-time players should use small chunks.
+func WithChunkSize(n int) Option {
+	return func(s *Session) {
+		if n > 0 {
+			s.chunkSize = n
+		}
+	}
+}
+
+// WithReports calls fn with the report of every utterance when it ended.
+func WithReports(fn func(Report)) Option {
+	return func(s *Session) {
+		s.onReport = fn
+	}
+}
+
+// Session plays queued utterances in order on a player.
+type Session struct {
+	synth     Synthesizer
+	out       io.Writer
+	prefetch  int
+	chunkSize int
+	onReport  func(Report)
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   []*Utterance // the first one is playing
+	playing *Utterance
+	nextID  int
+	closed  bool
+	stopped chan struct{}
+}
+
+// NewSession returns a session writing the audio of its utterances to out.
+func NewSession(synth Synthesizer, out io.Writer, options ...Option) *Session {
+	s := &Session{
+		synth:     synth,
+		out:       out,
+		prefetch:  1,
+		chunkSize: 1024,
+		stopped:   make(chan struct{}),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	for _, o := range options {
+		o(s)
+	}
+	go s.run()
+	return s
+}
+
+// Say queues request to be played after the utterances queued before.
+func (s *Session) Say(request *model.TextToSpeechRequest) (*Utterance, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, ErrClosed
+	}
+	s.nextID++
+	u := newUtterance(s.nextID, request)
+	s.queue = append(s.queue, u)
+	s.prefetchLocked()
+	s.cond.Signal()
+	return u, nil
+}
+
+// Cancel stops the playing utterance after the write in progress, aborts the synthesis of all queued
+// utterances and drops their buffered audio. It returns the reports of the stopped utterances once
+// the player is idle. The session stays usable.
+func (s *Session) Cancel() []Report {
+	s.mu.Lock()
+	stopped := s.queue
+	s.queue = nil
+	s.mu.Unlock()
+	return s.stop(stopped)
+}
+
+// Flush drops the utterances queued after the playing one, which still plays to its end, and returns
+// their reports.
+func (s *Session) Flush() []Report {
+	s.mu.Lock()
+	var stopped []*Utterance
+	for i, u := range s.queue {
+		if u != s.playing {
+			stopped = append(stopped, u)
+			continue
+		}
+		s.queue = s.queue[i : i+1]
+	}
+	if s.playing == nil {
+		s.queue = nil
+	}
+	s.mu.Unlock()
+	return s.stop(stopped)
+}
+
+// Wait waits until all utterances queued so far ended or ctx is done.
+func (s *Session) Wait(ctx context.Context) error {
+	s.mu.Lock()
+	queued := append([]*Utterance(nil), s.queue...)
+	s.mu.Unlock()
+	for _, u := range queued {
+		select {
+		case <-u.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Close cancels all utterances and stops the session.
+func (s *Session) Close() []Report {
+	s.mu.Lock()
+	s.closed = true
+	s.cond.Signal()
+	s.mu.Unlock()
+	reports := s.Cancel()
+	<-s.stopped
+	return reports
+}
+
+// stop cancels utterances removed from the queue and waits for them to end. The playing one is ended
+// by the player, the others right here.
+func (s *Session) stop(utterances []*Utterance) []Report {
+	for _, u := range utterances {
+		u.cancel()
+	}
+	s.mu.Lock()
+	playing := s.playing
+	s.mu.Unlock()
+
+	reports := make([]Report, 0, len(utterances))
+	for _, u := range utterances {
+		if u != playing {
+			s.finish(u, StatusCanceled, nil)
+		}
+		<-u.done
+		reports = append(reports, u.report)
+	}
+	return reports
+}
+
+func (s *Session) run() {
+	defer close(s.stopped)
+	for {
+		s.mu.Lock()
+		for len(s.queue) == 0 && !s.closed {
+			s.cond.Wait()
+		}
+		if len(s.queue) == 0 {
+			s.mu.Unlock()
+			return
+		}
+		u := s.queue[0]
+		s.playing = u
+		s.prefetchLocked()
+		s.mu.Unlock()
+
+		status, err := s.play(u)
+		s.finish(u, status, err)
+
+		s.mu.Lock()
+		if len(s.queue) > 0 && s.queue[0] == u {
+			s.queue = s.queue[1:]
+		}
+		s.playing = nil
+		s.prefetchLocked()
+		s.mu.Unlock()
+	}
+}
+
+// prefetchLocked starts synthesizing the playing utterance and the ones after it. s.mu must be held.
+func (s *Session) prefetchLocked() {
+	for i, u := range s.queue {
+		if i > s.prefetch {
+			return
+		}
+		u.start(s.synth)
+	}
+}
+
+func (s *Session) play(u *Utterance) (Status, error) {
+	for {
+		select {
+		case chunk, ok := <-u.chunks:
+			if !ok {
+				if u.ctx.Err() != nil {
+					return StatusCanceled, nil
+				}
+				if u.err != nil {
+					return StatusFailed, u.err
+				}
+				return StatusCompleted, nil
+			}
+			for len(chunk) > 0 {
+				if u.ctx.Err() != nil {
+					return StatusCanceled, nil
+				}
+				n := min(len(chunk), s.chunkSize)
+				written, err := s.out.Write(chunk[:n])
+				u.delivered += int64(written)
+				if err != nil {
+					u.cancel()
+					return StatusFailed, fmt.Errorf("playback: writing audio: %w", err)
+				}
+				chunk = chunk[n:]
+			}
+		case <-u.ctx.Done():
+			return StatusCanceled, nil
+		}
+	}
+}
+
+func (s *Session) finish(u *Utterance, status Status, err error) {
+	u.once.Do(func() {
+		u.cancel()
+		u.report = Report{
+			ID:        u.ID,
+			Request:   u.Request,
+			Status:    status,
+			Received:  u.received.Load(),
+			Delivered: u.delivered,
+			Err:       err,
+		}
+		if s.onReport != nil {
+			s.onReport(u.report)
+		}
+		close(u.done)
+	})
+}
@@ -0,0 +1,182 @@
+package playback_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+	"github.com/barkingdog-ai/azure-tts/playback"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSynthesizer answers every request with its text repeated to 1000 bytes and records which
+// requests were started and aborted.
+type fakeSynthesizer struct {
+	mu      sync.Mutex
+	started []string
+	aborted []string
+}
+
+func (s *fakeSynthesizer) TextToSpeechStream(ctx context.Context, request *model.TextToSpeechRequest) (io.ReadCloser, error) {
+	s.mu.Lock()
+	s.started = append(s.started, request.SpeechText)
+	s.mu.Unlock()
+	if request.SpeechText == "fail" {
+		return nil, errors.New("synthesis failed")
+	}
+	go func() {
+		<-ctx.Done()
+		s.mu.Lock()
+		s.aborted = append(s.aborted, request.SpeechText)
+		s.mu.Unlock()
+	}()
+	return io.NopCloser(strings.NewReader(strings.Repeat(request.SpeechText, 1000/len(request.SpeechText)))), nil
+}
+
+func (s *fakeSynthesizer) calls() (started, aborted []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.started...), append([]string(nil), s.aborted...)
+}
+
+// gatedPlayer blocks every write until it is released, like a real-time audio device.
+type gatedPlayer struct {
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	gate  chan struct{}
+	wrote chan struct{}
+}
+
+func newGatedPlayer() *gatedPlayer {
+	return &gatedPlayer{gate: make(chan struct{}), wrote: make(chan struct{}, 100)}
+}
+
+func (p *gatedPlayer) Write(b []byte) (int, error) {
+	<-p.gate
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.wrote <- struct{}{}
+	return p.buf.Write(b)
+}
+
+func (p *gatedPlayer) String() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.buf.String()
+}
+
+func say(t *testing.T, s *playback.Session, texts ...string) []*playback.Utterance {
+	t.Helper()
+	out := make([]*playback.Utterance, 0, len(texts))
+	for _, text := range texts {
+		u, err := s.Say(&model.TextToSpeechRequest{SpeechText: text})
+		require.NoError(t, err)
+		out = append(out, u)
+	}
+	return out
+}
+
+func TestSessionPlaysInOrder(t *testing.T) {
+	synth := &fakeSynthesizer{}
+	var out bytes.Buffer
+	var reports []playback.Report
+	s := playback.NewSession(synth, &out, playback.WithReports(func(r playback.Report) {
+		reports = append(reports, r)
+	}))
+	defer s.Close()
+
+	utterances := say(t, s, "a", "b", "fail", "c")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, s.Wait(ctx))
+
+	assert.Equal(t, strings.Repeat("a", 1000)+strings.Repeat("b", 1000)+strings.Repeat("c", 1000), out.String())
+	require.Len(t, reports, 4)
+	for i, want := range []playback.Status{playback.StatusCompleted, playback.StatusCompleted, playback.StatusFailed, playback.StatusCompleted} {
+		assert.Equal(t, utterances[i].ID, reports[i].ID)
+		assert.Equal(t, want, reports[i].Status, reports[i].Request.SpeechText)
+	}
+	assert.Equal(t, int64(1000), reports[0].Delivered)
+	assert.Equal(t, int64(1000), reports[0].Received)
+	assert.EqualError(t, reports[2].Err, "synthesis failed")
+}
+
+func TestSessionCancel(t *testing.T) {
+	synth := &fakeSynthesizer{}
+	player := newGatedPlayer()
+	s := playback.NewSession(synth, player, playback.WithChunkSize(100))
+	defer s.Close()
+
+	say(t, s, "a", "b", "c")
+	player.gate <- struct{}{}
+	<-player.wrote
+
+	assert.Eventually(t, func() bool {
+		started, _ := synth.calls()
+		return len(started) == 2
+	}, time.Second, time.Millisecond)
+	started, _ := synth.calls()
+	assert.ElementsMatch(t, []string{"a", "b"}, started, "only the next utterance is prefetched")
+
+	// the caller barges in while the second chunk waits for the player
+	done := make(chan []playback.Report)
+	go func() { done <- s.Cancel() }()
+	time.Sleep(10 * time.Millisecond)
+	close(player.gate)
+	reports := <-done
+
+	require.Len(t, reports, 3)
+	for _, r := range reports {
+		assert.Equal(t, playback.StatusCanceled, r.Status)
+	}
+	assert.LessOrEqual(t, reports[0].Delivered, int64(200))
+	assert.Equal(t, int64(0), reports[1].Delivered)
+	assert.Equal(t, int64(0), reports[2].Received, "never synthesized")
+	assert.Eventually(t, func() bool {
+		_, aborted := synth.calls()
+		return len(aborted) == 2
+	}, time.Second, time.Millisecond, "in-flight requests are aborted")
+
+	// the session stays usable
+	u := say(t, s, "d")[0]
+	r, err := u.Wait(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, playback.StatusCompleted, r.Status)
+	assert.True(t, strings.HasSuffix(player.String(), strings.Repeat("d", 1000)))
+}
+
+func TestSessionFlush(t *testing.T) {
+	synth := &fakeSynthesizer{}
+	player := newGatedPlayer()
+	s := playback.NewSession(synth, player, playback.WithChunkSize(100))
+	defer s.Close()
+
+	utterances := say(t, s, "a", "b")
+	player.gate <- struct{}{}
+	<-player.wrote
+
+	reports := s.Flush()
+	require.Len(t, reports, 1)
+	assert.Equal(t, utterances[1].ID, reports[0].ID)
+	assert.Equal(t, playback.StatusCanceled, reports[0].Status)
+
+	close(player.gate)
+	r, err := utterances[0].Wait(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, playback.StatusCompleted, r.Status)
+	assert.Equal(t, strings.Repeat("a", 1000), player.String())
+}
+
+func TestSessionClose(t *testing.T) {
+	s := playback.NewSession(&fakeSynthesizer{}, io.Discard)
+	s.Close()
+	_, err := s.Say(&model.TextToSpeechRequest{SpeechText: "a"})
+	assert.ErrorIs(t, err, playback.ErrClosed)
+}
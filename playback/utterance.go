@@ -0,0 +1,99 @@
+package playback
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+)
+
+// Utterance is a queued request.
+type Utterance struct {
+	ID      int
+	Request *model.TextToSpeechRequest
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	started bool
+	// chunks carries the received audio to the player, err is set before it is closed.
+	chunks   chan []byte
+	err      error
+	received atomic.Int64
+
+	delivered int64
+	once      sync.Once
+	report    Report
+	done      chan struct{}
+}
+
+func newUtterance(id int, request *model.TextToSpeechRequest) *Utterance {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Utterance{
+		ID:      id,
+		Request: request,
+		ctx:     ctx,
+		cancel:  cancel,
+		chunks:  make(chan []byte, 64),
+		done:    make(chan struct{}),
+	}
+}
+
+// Done returns a channel that is closed when the utterance ended.
+func (u *Utterance) Done() <-chan struct{} {
+	return u.done
+}
+
+// Report returns how the utterance ended. It must only be called after Done is closed.
+func (u *Utterance) Report() Report {
+	return u.report
+}
+
+// Wait waits until the utterance ended or ctx is done.
+func (u *Utterance) Wait(ctx context.Context) (Report, error) {
+	select {
+	case <-u.done:
+		return u.report, nil
+	case <-ctx.Done():
+		return Report{}, ctx.Err()
+	}
+}
+
+// start synthesizes the utterance in the background, once. The request is aborted when the utterance
+// is canceled.
+func (u *Utterance) start(synth Synthesizer) {
+	if u.started {
+		return
+	}
+	u.started = true
+	go func() {
+		defer close(u.chunks)
+		stream, err := synth.TextToSpeechStream(u.ctx, u.Request)
+		if err != nil {
+			u.err = err
+			return
+		}
+		defer stream.Close()
+		for {
+			buf := make([]byte, 4096)
+			n, err := stream.Read(buf)
+			if n > 0 {
+				u.received.Add(int64(n))
+				select {
+				case u.chunks <- buf[:n]:
+				case <-u.ctx.Done():
+					return
+				}
+			}
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				u.err = err
+				return
+			}
+		}
+	}()
+}
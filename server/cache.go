@@ -0,0 +1,85 @@
+package server
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+)
+
+// audioCache is a fixed size LRU of synthesized audio keyed by format and SSML.
+// A nil *audioCache caches nothing.
+type audioCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key   string
+	audio []byte
+}
+
+func newAudioCache(size int) *audioCache {
+	if size <= 0 {
+		return nil
+	}
+	return &audioCache{size: size, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+func (c *audioCache) get(key string) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*cacheEntry).audio, true
+}
+
+func (c *audioCache) add(key string, audio []byte) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, audio: append([]byte(nil), audio...)})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// voiceListCache keeps the voice catalog shared by every caller for ttl.
+type voiceListCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	voices  []model.VoiceListResponse
+	fetched time.Time
+}
+
+func (c *voiceListCache) get(ctx context.Context, client Synthesizer) ([]model.VoiceListResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.voices != nil && time.Since(c.fetched) < c.ttl {
+		return c.voices, nil
+	}
+	voices, err := client.VoiceList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.voices, c.fetched = *voices, time.Now()
+	return c.voices, nil
+}
@@ -0,0 +1,253 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	azuretts "github.com/barkingdog-ai/azure-tts"
+	API "github.com/barkingdog-ai/azure-tts/api"
+	"github.com/barkingdog-ai/azure-tts/model"
+)
+
+// streamChunkSize is the size of the audio chunks flushed to the caller while streaming.
+const streamChunkSize = 4096
+
+// SpeakRequest is the body of POST /v1/speak.
+type SpeakRequest struct {
+	Text   string `json:"text"`
+	Voice  string `json:"voice"`
+	Locale string `json:"locale"`
+	Gender string `json:"gender"`
+	Style  string `json:"style"`
+	Format string `json:"format"`
+	Rate   string `json:"rate"`
+	Pitch  string `json:"pitch"`
+	Volume string `json:"volume"`
+	Markup bool   `json:"markup"`
+}
+
+// reVoiceName matches a voice short name such as zh-TW-HsiaoChenNeural.
+var reVoiceName = regexp.MustCompile(`^[A-Za-z0-9]+(-[A-Za-z0-9]+)*$`)
+
+// toModel validates r and converts it into a synthesis request using the server defaults. Text is
+// plain text, SSML in it is spoken as written; only the inline markup enabled by Markup is rendered.
+func (s *Server) toModel(r *SpeakRequest) (*model.TextToSpeechRequest, error) {
+	if strings.TrimSpace(r.Text) == "" {
+		return nil, fmt.Errorf("text is required")
+	}
+	if n := utf8.RuneCountInString(r.Text); n > s.cfg.MaxTextLength {
+		return nil, fmt.Errorf("text is %d characters, the limit is %d", n, s.cfg.MaxTextLength)
+	}
+
+	req := &model.TextToSpeechRequest{
+		SpeechText:   r.Text,
+		VoiceName:    r.Voice,
+		Gender:       model.GenderFemale,
+		Prosody:      &model.Prosody{},
+		InlineMarkup: r.Markup,
+	}
+	// inline markup escapes its text while rendering
+	if !r.Markup {
+		req.SpeechText = API.EscapeText(r.Text)
+	}
+	if req.VoiceName == "" {
+		req.VoiceName = s.cfg.DefaultVoice
+	}
+	if !reVoiceName.MatchString(req.VoiceName) {
+		return nil, fmt.Errorf("invalid voice %q", req.VoiceName)
+	}
+
+	locale := r.Locale
+	if locale == "" {
+		locale = voiceLocale(req.VoiceName)
+	}
+	var err error
+	if req.Locale, err = model.ParseLocale(locale); err != nil {
+		return nil, fmt.Errorf("invalid locale %q", locale)
+	}
+	if r.Gender != "" {
+		if req.Gender, err = model.GenderString(r.Gender); err != nil {
+			return nil, fmt.Errorf("invalid gender %q", r.Gender)
+		}
+	}
+	format := r.Format
+	if format == "" {
+		format = s.cfg.DefaultFormat
+	}
+	if req.AudioOutput, err = model.StringToAudioOutput(format); err != nil {
+		return nil, err
+	}
+	if r.Style != "" {
+		if req.StyleProfile, err = azuretts.GetStyleProfile(r.Style); err != nil {
+			return nil, err
+		}
+	}
+	for _, v := range []struct {
+		text  string
+		value *model.ProsodyValue
+	}{{r.Rate, &req.Prosody.Rate}, {r.Pitch, &req.Prosody.Pitch}, {r.Volume, &req.Prosody.Volume}} {
+		if *v.value, err = model.ParseProsodyValue(v.text); err != nil {
+			return nil, err
+		}
+	}
+	if err := req.Prosody.Validate(); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func (s *Server) handleSpeak(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "use POST")
+		return
+	}
+	var body SpeakRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, s.cfg.MaxUploadBytes)).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "invalid JSON body: "+err.Error())
+		return
+	}
+	req, err := s.toModel(&body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", err.Error())
+		return
+	}
+	s.speak(w, r, req)
+}
+
+// speak writes the audio for req, from the cache when possible, otherwise streaming it from
+// Azure chunk by chunk while filling the cache.
+func (s *Server) speak(w http.ResponseWriter, r *http.Request, req *model.TextToSpeechRequest) {
+	ssml, err := API.BuildSSML(req)
+	if err != nil {
+		writeUpstreamError(w, err)
+		return
+	}
+	key := cacheKey(req.AudioOutput.String(), ssml)
+	if audio, ok := s.cache.get(key); ok {
+		w.Header().Set("Content-Type", req.AudioOutput.ContentType())
+		w.Header().Set("X-Cache", "HIT")
+		_, _ = w.Write(audio)
+		return
+	}
+
+	stream, err := s.client.TextToSpeechStream(r.Context(), req)
+	if err != nil {
+		writeUpstreamError(w, err)
+		return
+	}
+	defer stream.Close()
+
+	w.Header().Set("Content-Type", req.AudioOutput.ContentType())
+	w.Header().Set("X-Cache", "MISS")
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	var kept bytes.Buffer
+	cacheable := s.cache != nil
+	buf := make([]byte, streamChunkSize)
+	for {
+		n, err := stream.Read(buf)
+		if n > 0 {
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+			if cacheable {
+				kept.Write(buf[:n])
+				cacheable = kept.Len() <= s.cfg.CacheMaxBytes
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// headers are already sent, dropping the connection is the only way to signal the failure
+			panic(http.ErrAbortHandler)
+		}
+	}
+	if cacheable {
+		s.cache.add(key, kept.Bytes())
+	}
+}
+
+func (s *Server) handleVoices(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "use GET")
+		return
+	}
+	voices, err := s.voices.get(r.Context(), s.client)
+	if err != nil {
+		writeUpstreamError(w, err)
+		return
+	}
+
+	locale := strings.ToLower(r.URL.Query().Get("locale"))
+	filtered := make([]model.VoiceListResponse, 0, len(voices))
+	for i := range voices {
+		if locale == "" || strings.HasPrefix(strings.ToLower(voices[i].Locale), locale) {
+			filtered = append(filtered, voices[i])
+		}
+	}
+	writeJSON(w, http.StatusOK, filtered)
+}
+
+func (s *Server) handleTranscribe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "use POST")
+		return
+	}
+	language := r.URL.Query().Get("language")
+	if language == "" {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "language query parameter is required")
+		return
+	}
+
+	audio, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.cfg.MaxUploadBytes))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, "PayloadTooLarge", err.Error())
+		return
+	}
+	if len(audio) == 0 {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "audio body is empty")
+		return
+	}
+
+	resp, err := s.client.SpeechToText(r.Context(), model.SpeechToTextReq{Reader: bytes.NewReader(audio), Language: language})
+	if err != nil && !errors.Is(err, model.ErrNoMatch) {
+		writeUpstreamError(w, err)
+		return
+	}
+	if resp == nil {
+		resp = &model.SpeechToTextResp{}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// voiceLocale returns the locale prefix of an Azure voice name such as zh-TW-HsiaoChenNeural.
+func voiceLocale(voice string) string {
+	parts := strings.SplitN(voice, "-", 3)
+	if len(parts) != 3 {
+		return ""
+	}
+	return parts[0] + "-" + parts[1]
+}
+
+func cacheKey(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,221 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+)
+
+// DefaultVoiceAliases maps the OpenAI voice names onto Azure voices. Names that are not aliases
+// are passed to Azure unchanged, so callers may also use full Azure voice names.
+var DefaultVoiceAliases = map[string]string{
+	"alloy":   "zh-TW-HsiaoChenNeural",
+	"echo":    "zh-TW-YunJheNeural",
+	"fable":   "zh-TW-HsiaoYuNeural",
+	"onyx":    "zh-TW-YunJheNeural",
+	"nova":    "zh-TW-HsiaoChenNeural",
+	"shimmer": "zh-TW-HsiaoYuNeural",
+}
+
+// DefaultOpenAIFormats maps the OpenAI response_format values onto Azure outputs. Azure has no
+// opus, aac or flac output, those formats are rejected unless configured.
+var DefaultOpenAIFormats = map[string]model.AudioOutput{
+	"mp3": model.Audio24khz96kbitrateMonoMp3,
+	"wav": model.AudioRIFF24khz16bitMonoPcm,
+	"pcm": model.AudioRAW24khz16bitMonoPcm,
+}
+
+// languageLocales maps the ISO-639-1 languages OpenAI transcription accepts onto recognition locales.
+var languageLocales = map[string]string{
+	"zh": "zh-TW",
+	"en": "en-US",
+	"ja": "ja-JP",
+	"ko": "ko-KR",
+	"fr": "fr-FR",
+	"de": "de-DE",
+	"es": "es-ES",
+}
+
+// Azure accepts prosody rates between half and double speed, OpenAI speeds between 0.25 and 4.
+const (
+	minSpeed = 0.5
+	maxSpeed = 2.0
+)
+
+// OpenAISpeechRequest is the body of POST /v1/audio/speech.
+type OpenAISpeechRequest struct {
+	Model          string  `json:"model"`
+	Input          string  `json:"input"`
+	Voice          string  `json:"voice"`
+	ResponseFormat string  `json:"response_format"`
+	Speed          float64 `json:"speed"`
+}
+
+// OpenAITranscription is the json and verbose_json response of POST /v1/audio/transcriptions.
+type OpenAITranscription struct {
+	Task     string  `json:"task,omitempty"`
+	Language string  `json:"language,omitempty"`
+	Duration float64 `json:"duration,omitempty"`
+	Text     string  `json:"text"`
+}
+
+// toSpeak converts r into a SpeakRequest, resolving the voice alias and the response format. The
+// input is plain text, escaped by toModel. Speeds outside of the range Azure supports are clamped.
+func (s *Server) toSpeak(r *OpenAISpeechRequest) (*SpeakRequest, error) {
+	speak := &SpeakRequest{Text: r.Input, Voice: r.Voice}
+	if alias, ok := s.cfg.VoiceAliases[strings.ToLower(r.Voice)]; ok {
+		speak.Voice = alias
+	} else if r.Voice != "" && !reVoiceName.MatchString(r.Voice) {
+		return nil, fmt.Errorf("unknown voice %q, use an alias or an Azure voice name", r.Voice)
+	}
+
+	format := strings.ToLower(r.ResponseFormat)
+	if format == "" {
+		format = "mp3"
+	}
+	output, ok := s.cfg.OpenAIFormats[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported response_format %q", r.ResponseFormat)
+	}
+	speak.Format = output.String()
+
+	if r.Speed != 0 && r.Speed != 1 {
+		speed := r.Speed
+		if speed < minSpeed {
+			speed = minSpeed
+		} else if speed > maxSpeed {
+			speed = maxSpeed
+		}
+		speak.Rate = strconv.FormatFloat(speed, 'f', -1, 64)
+	}
+	return speak, nil
+}
+
+func (s *Server) handleOpenAISpeech(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "use POST")
+		return
+	}
+	var body OpenAISpeechRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, s.cfg.MaxUploadBytes)).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "invalid JSON body: "+err.Error())
+		return
+	}
+	speak, err := s.toSpeak(&body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+	req, err := s.toModel(speak)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+	s.speak(w, r, req)
+}
+
+// transcriptionLocale picks the recognition locale for an OpenAI language, falling back to the
+// locale of the default voice.
+func (s *Server) transcriptionLocale(language string) string {
+	if language == "" {
+		return voiceLocale(s.cfg.DefaultVoice)
+	}
+	if strings.Contains(language, "-") {
+		return language
+	}
+	if locale, ok := languageLocales[strings.ToLower(language)]; ok {
+		return locale
+	}
+	return language
+}
+
+// wavContentTypes are the media types of WAV files.
+var wavContentTypes = []string{"audio/wav", "audio/x-wav", "audio/wave", "audio/vnd.wave"}
+
+// isWAVUpload reports whether the uploaded file is a WAV file by its extension or media type. The
+// audio is sent to Azure as WAV, which can't decode the mp3, m4a or webm files OpenAI also accepts.
+func isWAVUpload(header *multipart.FileHeader) bool {
+	if strings.EqualFold(path.Ext(header.Filename), ".wav") {
+		return true
+	}
+	mediaType, _, _ := mime.ParseMediaType(header.Header.Get("Content-Type"))
+	for _, t := range wavContentTypes {
+		if strings.EqualFold(mediaType, t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) handleOpenAITranscription(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "use POST")
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, s.cfg.MaxUploadBytes)
+	if err := r.ParseMultipartForm(s.cfg.MaxUploadBytes); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "invalid multipart body: "+err.Error())
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "file is required")
+		return
+	}
+	defer file.Close()
+	if !isWAVUpload(header) {
+		writeError(w, http.StatusBadRequest, "invalid_request_error",
+			fmt.Sprintf("unsupported audio file %q, only WAV (16 kHz, 16-bit mono PCM) can be transcribed", header.Filename))
+		return
+	}
+	audio, err := io.ReadAll(file)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+
+	format := r.FormValue("response_format")
+	switch format {
+	case "", "json", "text", "verbose_json":
+	default:
+		writeError(w, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("unsupported response_format %q", format))
+		return
+	}
+
+	locale := s.transcriptionLocale(r.FormValue("language"))
+	resp, err := s.client.SpeechToText(r.Context(), model.SpeechToTextReq{Reader: bytes.NewReader(audio), Language: locale})
+	// OpenAI reports audio without speech as an empty transcription
+	if err != nil && !errors.Is(err, model.ErrNoMatch) {
+		writeUpstreamError(w, err)
+		return
+	}
+	if resp == nil {
+		resp = &model.SpeechToTextResp{}
+	}
+
+	switch format {
+	case "text":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = io.WriteString(w, resp.DisplayText)
+	case "verbose_json":
+		writeJSON(w, http.StatusOK, OpenAITranscription{
+			Task:     "transcribe",
+			Language: locale,
+			// Duration is reported in 100-nanosecond ticks
+			Duration: float64(resp.Duration) / 1e7,
+			Text:     resp.DisplayText,
+		})
+	default:
+		writeJSON(w, http.StatusOK, OpenAITranscription{Text: resp.DisplayText})
+	}
+}
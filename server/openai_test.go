@@ -0,0 +1,125 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenAISpeech(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantVoice  string
+		wantOutput model.AudioOutput
+		wantRate   string
+		wantType   string
+		wantText   string
+	}{
+		{
+			name: "alias", body: `{"model":"tts-1","input":"你好","voice":"alloy"}`,
+			wantStatus: http.StatusOK, wantVoice: "zh-TW-HsiaoChenNeural", wantOutput: model.Audio24khz96kbitrateMonoMp3, wantType: "audio/mpeg",
+		},
+		{
+			name: "azure voice and wav", body: `{"input":"hello","voice":"en-US-JennyNeural","response_format":"wav","speed":1.25}`,
+			wantStatus: http.StatusOK, wantVoice: "en-US-JennyNeural", wantOutput: model.AudioRIFF24khz16bitMonoPcm, wantRate: "1.25", wantType: "audio/wav",
+		},
+		{
+			name: "speed clamped", body: `{"input":"你好","voice":"echo","response_format":"pcm","speed":4}`,
+			wantStatus: http.StatusOK, wantVoice: "zh-TW-YunJheNeural", wantOutput: model.AudioRAW24khz16bitMonoPcm, wantRate: "2", wantType: "audio/pcm",
+		},
+		{name: "unsupported format", body: `{"input":"你好","voice":"alloy","response_format":"opus"}`, wantStatus: http.StatusBadRequest},
+		{name: "empty input", body: `{"input":"","voice":"alloy"}`, wantStatus: http.StatusBadRequest},
+		{name: "unknown voice", body: `{"input":"你好","voice":"nova\" name=\"x"}`, wantStatus: http.StatusBadRequest},
+		{
+			name: "chat text is escaped", body: `{"input":"1 < 2 & 3 > 2","voice":"alloy"}`,
+			wantStatus: http.StatusOK, wantVoice: "zh-TW-HsiaoChenNeural", wantOutput: model.Audio24khz96kbitrateMonoMp3, wantType: "audio/mpeg",
+			wantText: "1 &lt; 2 &amp; 3 &gt; 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeSynthesizer{}
+			w := httptest.NewRecorder()
+			newServer(t, fake, Config{AllowUnauthenticated: true}).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/audio/speech", bytes.NewBufferString(tt.body)))
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus != http.StatusOK {
+				assert.Equal(t, 0, fake.calls)
+				return
+			}
+			assert.Equal(t, tt.wantType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.wantVoice, fake.lastReq.VoiceName)
+			assert.Equal(t, tt.wantOutput, fake.lastReq.AudioOutput)
+			rate := ""
+			if !fake.lastReq.Prosody.Rate.IsZero() {
+				rate = fake.lastReq.Prosody.Rate.String()
+			}
+			assert.Equal(t, tt.wantRate, rate)
+			if tt.wantText != "" {
+				assert.Equal(t, tt.wantText, fake.lastReq.SpeechText)
+			}
+		})
+	}
+}
+
+func TestOpenAITranscription(t *testing.T) {
+	tests := []struct {
+		format   string
+		wantType string
+		wantBody string
+	}{
+		{format: "", wantType: "application/json", wantBody: `{"text":"wav"}`},
+		{format: "text", wantType: "text/plain; charset=utf-8", wantBody: "wav"},
+		{format: "verbose_json", wantType: "application/json", wantBody: `{"task":"transcribe","language":"en-US","text":"wav"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var body bytes.Buffer
+			mw := multipart.NewWriter(&body)
+			part, _ := mw.CreateFormFile("file", "speech.wav")
+			_, _ = part.Write([]byte("wav"))
+			_ = mw.WriteField("model", "whisper-1")
+			_ = mw.WriteField("language", "en")
+			_ = mw.WriteField("response_format", tt.format)
+			_ = mw.Close()
+
+			r := httptest.NewRequest(http.MethodPost, "/v1/audio/transcriptions", &body)
+			r.Header.Set("Content-Type", mw.FormDataContentType())
+			w := httptest.NewRecorder()
+			newServer(t, &fakeSynthesizer{}, Config{AllowUnauthenticated: true}).ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.wantType, w.Header().Get("Content-Type"))
+			if tt.wantType == "application/json" {
+				var got json.RawMessage
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+				assert.JSONEq(t, tt.wantBody, string(got))
+			} else {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+
+	for _, name := range []string{"speech.mp3", "speech.m4a", "speech.webm"} {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		part, _ := mw.CreateFormFile("file", name)
+		_, _ = part.Write([]byte("audio"))
+		_ = mw.Close()
+
+		r := httptest.NewRequest(http.MethodPost, "/v1/audio/transcriptions", &body)
+		r.Header.Set("Content-Type", mw.FormDataContentType())
+		w := httptest.NewRecorder()
+		newServer(t, &fakeSynthesizer{}, Config{AllowUnauthenticated: true}).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, name)
+		assert.Contains(t, w.Body.String(), "invalid_request_error", name)
+	}
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often buckets of idle callers are evicted.
+const sweepInterval = time.Minute
+
+// rateLimiter is a token bucket per caller. Buckets that have refilled are evicted, which loses
+// nothing since a new bucket starts full.
+type rateLimiter struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     float64
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{rate: rate, burst: float64(burst), buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// reserve takes a token for caller. It returns 0 when the request may proceed, otherwise how long
+// the caller has to wait for the next token.
+func (l *rateLimiter) reserve(caller string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= sweepInterval {
+		l.sweep(now)
+	}
+	b, ok := l.buckets[caller]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[caller] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.burst {
+		b.tokens = l.burst
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	b.tokens--
+	return 0
+}
+
+// sweep evicts the buckets that are full again at now.
+func (l *rateLimiter) sweep(now time.Time) {
+	for caller, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, caller)
+		}
+	}
+	l.lastSweep = now
+}
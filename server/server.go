@@ -0,0 +1,227 @@
+// Package server exposes an AzureTTSClient to internal services over a small REST API so that the
+// Azure subscription key stays in one place.
+//
+//	POST /v1/speak       JSON speak request, responds with streamed audio
+//	GET  /v1/voices      voice catalog as JSON, optionally filtered with ?locale=
+//	POST /v1/transcribe  audio body, ?language=zh-TW, responds with the recognition result
+//
+// The OpenAI audio API is served as well, so OpenAI clients only need a different base URL:
+//
+//	POST /v1/audio/speech          OpenAI speech request, voices mapped through Config.VoiceAliases
+//	POST /v1/audio/transcriptions  OpenAI multipart transcription request
+//
+// Callers authenticate with "Authorization: Bearer <key>" or "X-API-Key: <key>".
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+)
+
+// Synthesizer is the part of the Azure client the server needs.
+type Synthesizer interface {
+	TextToSpeechStream(ctx context.Context, req *model.TextToSpeechRequest) (io.ReadCloser, error)
+	SpeechToText(ctx context.Context, req model.SpeechToTextReq) (*model.SpeechToTextResp, error)
+	VoiceList(ctx context.Context) (*[]model.VoiceListResponse, error)
+}
+
+// Config controls authentication, limits and caching of a Server. Zero limits and sizes disable the
+// feature or use a default.
+type Config struct {
+	// APIKeys are the keys callers may present. At least one is required unless AllowUnauthenticated
+	// is set.
+	APIKeys []string
+	// AllowUnauthenticated serves callers without an API key when APIKeys is empty. Anyone who can
+	// reach the server can then spend the Azure subscription.
+	AllowUnauthenticated bool
+	// RateLimit is the number of requests per second allowed for each caller, with bursts of RateBurst.
+	RateLimit float64
+	RateBurst int
+	// CacheEntries is the number of synthesized clips kept in memory, each at most CacheMaxBytes long.
+	CacheEntries  int
+	CacheMaxBytes int
+	// VoiceListTTL is how long the voice catalog is served from memory.
+	VoiceListTTL time.Duration
+	// MaxTextLength limits the characters of a speak request, MaxUploadBytes the size of a transcribe upload.
+	MaxTextLength  int
+	MaxUploadBytes int64
+	// DefaultVoice and DefaultFormat are used when a speak request leaves them out.
+	DefaultVoice  string
+	DefaultFormat string
+	// VoiceAliases and OpenAIFormats translate OpenAI voices and response formats, they default to
+	// DefaultVoiceAliases and DefaultOpenAIFormats.
+	VoiceAliases  map[string]string
+	OpenAIFormats map[string]model.AudioOutput
+}
+
+const (
+	defaultMaxTextLength  = 5000
+	defaultMaxUploadBytes = 10 << 20
+	defaultCacheMaxBytes  = 1 << 20
+	defaultVoiceListTTL   = time.Hour
+	defaultVoice          = "zh-TW-HsiaoChenNeural"
+	defaultFormat         = "audio-16khz-32kbitrate-mono-mp3"
+)
+
+// Server is an http.Handler serving the REST API.
+type Server struct {
+	client  Synthesizer
+	cfg     Config
+	cache   *audioCache
+	voices  *voiceListCache
+	limiter *rateLimiter
+	mux     *http.ServeMux
+}
+
+// ErrNoAPIKeys is returned by New when Config has neither APIKeys nor AllowUnauthenticated.
+var ErrNoAPIKeys = errors.New("server: no API keys configured and unauthenticated access not allowed")
+
+// New returns a Server forwarding requests to client. It fails with ErrNoAPIKeys unless callers must
+// authenticate or Config.AllowUnauthenticated is set.
+func New(client Synthesizer, cfg Config) (*Server, error) {
+	if len(cfg.APIKeys) == 0 && !cfg.AllowUnauthenticated {
+		return nil, ErrNoAPIKeys
+	}
+	if cfg.MaxTextLength <= 0 {
+		cfg.MaxTextLength = defaultMaxTextLength
+	}
+	if cfg.MaxUploadBytes <= 0 {
+		cfg.MaxUploadBytes = defaultMaxUploadBytes
+	}
+	if cfg.CacheMaxBytes <= 0 {
+		cfg.CacheMaxBytes = defaultCacheMaxBytes
+	}
+	if cfg.VoiceListTTL <= 0 {
+		cfg.VoiceListTTL = defaultVoiceListTTL
+	}
+	if cfg.DefaultVoice == "" {
+		cfg.DefaultVoice = defaultVoice
+	}
+	if cfg.DefaultFormat == "" {
+		cfg.DefaultFormat = defaultFormat
+	}
+	if cfg.VoiceAliases == nil {
+		cfg.VoiceAliases = DefaultVoiceAliases
+	}
+	if cfg.OpenAIFormats == nil {
+		cfg.OpenAIFormats = DefaultOpenAIFormats
+	}
+
+	s := &Server{
+		client: client,
+		cfg:    cfg,
+		cache:  newAudioCache(cfg.CacheEntries),
+		voices: &voiceListCache{ttl: cfg.VoiceListTTL},
+		mux:    http.NewServeMux(),
+	}
+	if cfg.RateLimit > 0 {
+		s.limiter = newRateLimiter(cfg.RateLimit, cfg.RateBurst)
+	}
+	s.Handle("/v1/speak", http.HandlerFunc(s.handleSpeak))
+	s.Handle("/v1/voices", http.HandlerFunc(s.handleVoices))
+	s.Handle("/v1/transcribe", http.HandlerFunc(s.handleTranscribe))
+	s.Handle("/v1/audio/speech", http.HandlerFunc(s.handleOpenAISpeech))
+	s.Handle("/v1/audio/transcriptions", http.HandlerFunc(s.handleOpenAITranscription))
+	return s, nil
+}
+
+// Handle registers an additional handler behind the server's authentication and rate limiting.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, s.guard(handler))
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// guard authenticates the caller and applies its rate limit before calling next.
+func (s *Server) guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := s.authenticate(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="azuretts"`)
+			writeError(w, http.StatusUnauthorized, "Unauthorized", "missing or invalid API key")
+			return
+		}
+		if s.limiter != nil {
+			if wait := s.limiter.reserve(caller); wait > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				writeError(w, http.StatusTooManyRequests, "Throttled", "rate limit exceeded")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authenticate returns the identity used for rate limiting: the API key, or the remote address
+// when unauthenticated access is allowed.
+func (s *Server) authenticate(r *http.Request) (string, bool) {
+	if len(s.cfg.APIKeys) == 0 {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		return host, true
+	}
+
+	key := r.Header.Get("X-API-Key")
+	if auth := r.Header.Get("Authorization"); key == "" && strings.HasPrefix(auth, "Bearer ") {
+		key = strings.TrimPrefix(auth, "Bearer ")
+	}
+	if key == "" {
+		return "", false
+	}
+	for _, allowed := range s.cfg.APIKeys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(allowed)) == 1 {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// writeError writes an error body shaped like the Azure error responses.
+func writeError(w http.ResponseWriter, status int, errType, message string) {
+	writeJSON(w, status, model.APIErrorResponse{Error: model.APIError{StatusCode: status, Type: errType, Message: message}})
+}
+
+// writeUpstreamError reports a failed Azure call, passing client errors through and turning
+// everything else into a 502. A rejected Azure key is the gateway's fault, not the caller's.
+func writeUpstreamError(w http.ResponseWriter, err error) {
+	if retryAfter, ok := model.RetryAfter(err); ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+	var apiErr model.APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode >= 400 && apiErr.StatusCode < 500 && !errors.Is(err, model.ErrUnauthorized) {
+		writeError(w, apiErr.StatusCode, apiErr.Type, apiErr.Message)
+		return
+	}
+	if errors.Is(err, model.ErrTimeout) {
+		writeError(w, http.StatusGatewayTimeout, "Timeout", err.Error())
+		return
+	}
+	var prosodyErr *model.ProsodyError
+	var markupErr *model.MarkupError
+	if errors.As(err, &prosodyErr) || errors.As(err, &markupErr) {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", err.Error())
+		return
+	}
+	writeError(w, http.StatusBadGateway, "Upstream", err.Error())
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
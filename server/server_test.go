@@ -0,0 +1,186 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	API "github.com/barkingdog-ai/azure-tts/api"
+	"github.com/barkingdog-ai/azure-tts/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSynthesizer struct {
+	calls   int
+	lastReq *model.TextToSpeechRequest
+	err     error
+}
+
+func (f *fakeSynthesizer) TextToSpeechStream(_ context.Context, req *model.TextToSpeechRequest) (io.ReadCloser, error) {
+	f.calls++
+	f.lastReq = req
+	if f.err != nil {
+		return nil, f.err
+	}
+	return io.NopCloser(strings.NewReader("audio:" + req.SpeechText)), nil
+}
+
+func (f *fakeSynthesizer) SpeechToText(_ context.Context, req model.SpeechToTextReq) (*model.SpeechToTextResp, error) {
+	data, _ := io.ReadAll(req.Reader)
+	return &model.SpeechToTextResp{RecognitionStatus: "Success", DisplayText: string(data)}, nil
+}
+
+func (f *fakeSynthesizer) VoiceList(context.Context) (*[]model.VoiceListResponse, error) {
+	return &[]model.VoiceListResponse{
+		{ShortName: "zh-TW-HsiaoChenNeural", Locale: "zh-TW"},
+		{ShortName: "en-US-JennyNeural", Locale: "en-US"},
+	}, nil
+}
+
+func newServer(t *testing.T, client Synthesizer, cfg Config) *Server {
+	t.Helper()
+	s, err := New(client, cfg)
+	require.NoError(t, err)
+	return s
+}
+
+func speak(h http.Handler, key, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/v1/speak", strings.NewReader(body))
+	if key != "" {
+		r.Header.Set("Authorization", "Bearer "+key)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestSpeak(t *testing.T) {
+	fake := &fakeSynthesizer{}
+	s := newServer(t, fake, Config{APIKeys: []string{"secret"}, CacheEntries: 8})
+
+	tests := []struct {
+		name        string
+		key         string
+		body        string
+		wantStatus  int
+		wantType    string
+		wantBody    string
+		wantCache   string
+		wantOutput  model.AudioOutput
+		wantUpcalls int
+	}{
+		{name: "missing key", body: `{"text":"你好"}`, wantStatus: http.StatusUnauthorized},
+		{name: "wrong key", key: "nope", body: `{"text":"你好"}`, wantStatus: http.StatusUnauthorized},
+		{name: "empty text", key: "secret", body: `{"text":" "}`, wantStatus: http.StatusBadRequest},
+		{name: "bad rate", key: "secret", body: `{"text":"你好","rate":"+500%"}`, wantStatus: http.StatusBadRequest},
+		{name: "bad format", key: "secret", body: `{"text":"你好","format":"flac"}`, wantStatus: http.StatusBadRequest},
+		{name: "bad voice", key: "secret", body: `{"text":"你好","voice":"a' name='b"}`, wantStatus: http.StatusBadRequest},
+		{
+			name: "miss", key: "secret", body: `{"text":"你好","format":"riff-24khz-16bit-mono-pcm"}`,
+			wantStatus: http.StatusOK, wantType: "audio/wav", wantBody: "audio:你好", wantCache: "MISS",
+			wantOutput: model.AudioRIFF24khz16bitMonoPcm, wantUpcalls: 1,
+		},
+		{
+			name: "hit", key: "secret", body: `{"text":"你好","format":"riff-24khz-16bit-mono-pcm"}`,
+			wantStatus: http.StatusOK, wantType: "audio/wav", wantBody: "audio:你好", wantCache: "HIT",
+			wantUpcalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := speak(s, tt.key, tt.body)
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantUpcalls, fake.calls)
+			if tt.wantStatus != http.StatusOK {
+				var resp model.APIErrorResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, tt.wantStatus, resp.Error.StatusCode)
+				return
+			}
+			assert.Equal(t, tt.wantType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.wantCache, w.Header().Get("X-Cache"))
+			assert.Equal(t, tt.wantBody, w.Body.String())
+			if tt.wantCache == "MISS" {
+				assert.Equal(t, tt.wantOutput, fake.lastReq.AudioOutput)
+				assert.Equal(t, model.LocaleZhTW, fake.lastReq.Locale)
+			}
+		})
+	}
+}
+
+func TestNewRequiresAPIKeys(t *testing.T) {
+	_, err := New(&fakeSynthesizer{}, Config{})
+	assert.ErrorIs(t, err, ErrNoAPIKeys)
+}
+
+func TestSpeakEscapesText(t *testing.T) {
+	fake := &fakeSynthesizer{}
+	w := speak(newServer(t, fake, Config{AllowUnauthenticated: true}), "", `{"text":"Tom & Jerry <3","voice":"en-US-JennyNeural"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Tom &amp; Jerry &lt;3", fake.lastReq.SpeechText)
+
+	ssml, err := API.BuildSSML(fake.lastReq)
+	require.NoError(t, err)
+	assert.NoError(t, xml.Unmarshal([]byte(ssml), new(struct{})))
+}
+
+func TestSpeakUpstreamError(t *testing.T) {
+	fake := &fakeSynthesizer{err: model.APIError{StatusCode: http.StatusBadRequest, Type: "BadRequest", Message: "bad ssml"}}
+	w := speak(newServer(t, fake, Config{AllowUnauthenticated: true}), "", `{"text":"hi","voice":"en-US-JennyNeural"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	fake.err = io.ErrUnexpectedEOF
+	w = speak(newServer(t, fake, Config{AllowUnauthenticated: true}), "", `{"text":"hi","voice":"en-US-JennyNeural"}`)
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+}
+
+func TestRateLimit(t *testing.T) {
+	s := newServer(t, &fakeSynthesizer{}, Config{APIKeys: []string{"a", "b"}, RateLimit: 1, RateBurst: 2})
+	now := time.Unix(0, 0)
+	s.limiter.now = func() time.Time { return now }
+
+	assert.Equal(t, http.StatusOK, speak(s, "a", `{"text":"1"}`).Code)
+	assert.Equal(t, http.StatusOK, speak(s, "a", `{"text":"2"}`).Code)
+	w := speak(s, "a", `{"text":"3"}`)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusOK, speak(s, "b", `{"text":"1"}`).Code)
+
+	now = now.Add(time.Second)
+	assert.Equal(t, http.StatusOK, speak(s, "a", `{"text":"4"}`).Code)
+	assert.Len(t, s.limiter.buckets, 2)
+
+	now = now.Add(sweepInterval)
+	assert.Equal(t, http.StatusOK, speak(s, "b", `{"text":"2"}`).Code)
+	assert.Len(t, s.limiter.buckets, 1, "idle buckets are evicted")
+}
+
+func TestVoicesAndTranscribe(t *testing.T) {
+	s := newServer(t, &fakeSynthesizer{}, Config{AllowUnauthenticated: true})
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/voices?locale=en", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var voices []model.VoiceListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &voices))
+	assert.Len(t, voices, 1)
+	assert.Equal(t, "en-US-JennyNeural", voices[0].ShortName)
+
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/transcribe", strings.NewReader("wav")))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/transcribe?language=zh-TW", strings.NewReader("wav")))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp model.SpeechToTextResp
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "wav", resp.DisplayText)
+}
@@ -0,0 +1,196 @@
+// Package streaming synthesizes text while it is still being written, e.g. the token stream of a
+// language model, so speech starts after the first sentence instead of the whole reply:
+//
+//	s := streaming.NewIncrementalSynthesizer(ctx, client, &model.TextToSpeechRequest{
+//		VoiceName:   "zh-TW-HsiaoChenNeural",
+//		AudioOutput: model.Audio24khz48kbitrateMonoMp3,
+//	})
+//	go func() {
+//		for delta := range tokens {
+//			io.WriteString(s, delta)
+//		}
+//		s.Close()
+//	}()
+//	io.Copy(player, s)
+package streaming
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+)
+
+// ErrClosed is returned by Write after Close.
+var ErrClosed = errors.New("streaming: synthesizer closed")
+
+// Synthesizer is implemented by api.AzureTTSClient.
+type Synthesizer interface {
+	TextToSpeechStream(ctx context.Context, request *model.TextToSpeechRequest) (io.ReadCloser, error)
+}
+
+// Option configures an IncrementalSynthesizer.
+type Option func(*IncrementalSynthesizer)
+
+// WithMinLength sets the minimum length of a synthesized segment in runes, DefaultMinLength by default.
+func WithMinLength(n int) Option {
+	return func(s *IncrementalSynthesizer) {
+		s.segmenter.minLength = n
+	}
+}
+
+// WithConcurrency sets how many segments are synthesized at the same time, 2 by default. Segments
+// after the one being read are fetched ahead, so the audio has no gaps between them.
+func WithConcurrency(n int) Option {
+	return func(s *IncrementalSynthesizer) {
+		if n > 0 {
+			s.concurrency = n
+		}
+	}
+}
+
+// IncrementalSynthesizer is an io.WriteCloser for text and an io.Reader for its speech. Complete
+// sentences and clauses are sent to synthesis as soon as they are written, and their audio is read
+// in the order of the text. The audio of the segments is concatenated, so the output format should
+// be one without a header, such as MP3 or raw PCM.
+type IncrementalSynthesizer struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	synth       Synthesizer
+	template    model.TextToSpeechRequest
+	concurrency int
+
+	mu        sync.Mutex
+	segmenter *Segmenter
+	closed    bool
+	segments  chan string
+
+	pr *io.PipeReader
+	pw *io.PipeWriter
+}
+
+// segment is a dispatched sentence and the result of its synthesis.
+type segment struct {
+	stream io.ReadCloser
+	err    error
+	ready  chan struct{}
+}
+
+// NewIncrementalSynthesizer returns an IncrementalSynthesizer synthesizing segments with the settings
+// of template. Canceling ctx aborts synthesis and fails Read and Write.
+func NewIncrementalSynthesizer(ctx context.Context, synth Synthesizer, template *model.TextToSpeechRequest,
+	options ...Option,
+) *IncrementalSynthesizer {
+	ctx, cancel := context.WithCancel(ctx)
+	pr, pw := io.Pipe()
+	s := &IncrementalSynthesizer{
+		ctx:         ctx,
+		cancel:      cancel,
+		synth:       synth,
+		template:    *template,
+		concurrency: 2,
+		segmenter:   NewSegmenter(DefaultMinLength),
+		segments:    make(chan string, 64),
+		pr:          pr,
+		pw:          pw,
+	}
+	for _, o := range options {
+		o(s)
+	}
+
+	ordered := make(chan *segment, s.concurrency)
+	slots := make(chan struct{}, s.concurrency)
+	go s.dispatch(ordered, slots)
+	go s.output(ordered, slots)
+	context.AfterFunc(ctx, func() {
+		pw.CloseWithError(ctx.Err())
+	})
+	return s
+}
+
+// Write adds a text delta. It returns once the segments it completed were queued for synthesis.
+func (s *IncrementalSynthesizer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0, ErrClosed
+	}
+	return len(p), s.queue(s.segmenter.Write(string(p)))
+}
+
+// Close ends the text. The remaining text is synthesized as the last segment and Read returns
+// io.EOF after its audio.
+func (s *IncrementalSynthesizer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	err := s.queue(s.segmenter.Flush())
+	close(s.segments)
+	return err
+}
+
+// Read reads the audio of the segments in order.
+func (s *IncrementalSynthesizer) Read(p []byte) (int, error) {
+	return s.pr.Read(p)
+}
+
+func (s *IncrementalSynthesizer) queue(texts []string) error {
+	for _, text := range texts {
+		select {
+		case s.segments <- text:
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		}
+	}
+	return nil
+}
+
+// dispatch starts synthesizing the segments in order, taking a slot for each, and hands them to the
+// output in the same order. Taking the slots in order guarantees the segment read next always gets one.
+func (s *IncrementalSynthesizer) dispatch(ordered chan<- *segment, slots chan struct{}) {
+	defer close(ordered)
+	for text := range s.segments {
+		select {
+		case slots <- struct{}{}:
+		case <-s.ctx.Done():
+			return
+		}
+		seg := &segment{ready: make(chan struct{})}
+		request := s.template
+		request.SpeechText = text
+		go func() {
+			defer close(seg.ready)
+			seg.stream, seg.err = s.synth.TextToSpeechStream(s.ctx, &request)
+		}()
+		ordered <- seg
+	}
+}
+
+// output copies the audio of the segments to the pipe, freeing the slot of each when it was read.
+// The first failure ends the audio with its error and aborts the other segments.
+func (s *IncrementalSynthesizer) output(ordered <-chan *segment, slots <-chan struct{}) {
+	defer s.cancel()
+	failed := false
+	for seg := range ordered {
+		<-seg.ready
+		err := seg.err
+		if seg.stream != nil {
+			if err == nil && !failed {
+				_, err = io.Copy(s.pw, seg.stream)
+			}
+			seg.stream.Close()
+		}
+		if err != nil && !failed {
+			failed = true
+			s.pw.CloseWithError(err)
+			s.cancel()
+		}
+		<-slots
+	}
+	s.pw.CloseWithError(s.ctx.Err())
+}
@@ -0,0 +1,114 @@
+package streaming
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultMinLength is the default minimum length of a segment in runes.
+const DefaultMinLength = 10
+
+// Segmenter splits streamed text into sentences and clauses. Segments shorter than the minimum
+// length are joined with the text after them, so short interjections aren't synthesized on their own.
+type Segmenter struct {
+	minLength int
+	buf       strings.Builder
+}
+
+// NewSegmenter returns a Segmenter emitting segments of at least minLength runes, except for the last.
+func NewSegmenter(minLength int) *Segmenter {
+	return &Segmenter{minLength: minLength}
+}
+
+// Write adds text and returns the segments completed by it.
+func (s *Segmenter) Write(text string) []string {
+	s.buf.WriteString(text)
+	return s.split(false)
+}
+
+// Flush returns the rest of the text as the last segment, if it isn't blank.
+func (s *Segmenter) Flush() []string {
+	return s.split(true)
+}
+
+// split cuts complete segments off the buffer. A Latin punctuation mark only ends a segment when
+// followed by white space, so "3.14" or "example.com" stay intact, which needs the next text or the end.
+func (s *Segmenter) split(final bool) []string {
+	text := s.buf.String()
+	var segments []string
+	start := 0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		end := i + size
+		switch {
+		case isCJKBoundary(r):
+		case isLatinBoundary(r):
+			next, _ := utf8.DecodeRuneInString(text[end:])
+			if end == len(text) && !final {
+				i = len(text)
+				continue
+			}
+			if end < len(text) && !unicode.IsSpace(next) && !isCloser(next) && !isLatinBoundary(next) {
+				i = end
+				continue
+			}
+		case r == '\n':
+		default:
+			i = end
+			continue
+		}
+
+		// keep runs of marks and closing quotes or brackets with the segment
+		for end < len(text) {
+			next, n := utf8.DecodeRuneInString(text[end:])
+			if !isCloser(next) && !isCJKBoundary(next) && !isLatinBoundary(next) {
+				break
+			}
+			end += n
+		}
+		if segment := strings.TrimSpace(text[start:end]); utf8.RuneCountInString(segment) >= s.minLength {
+			segments = append(segments, segment)
+			start = end
+		}
+		i = end
+	}
+
+	rest := text[start:]
+	if final {
+		if segment := strings.TrimSpace(rest); segment != "" {
+			segments = append(segments, segment)
+		}
+		rest = ""
+	}
+	s.buf.Reset()
+	s.buf.WriteString(rest)
+	return segments
+}
+
+// isCJKBoundary reports whether r ends a sentence or clause in Chinese or Japanese text. Full-width
+// marks end a segment right away.
+func isCJKBoundary(r rune) bool {
+	switch r {
+	case '。', '！', '？', '；', '，', '、', '：', '…', '．':
+		return true
+	}
+	return false
+}
+
+// isLatinBoundary reports whether r ends a sentence or clause in Latin text.
+func isLatinBoundary(r rune) bool {
+	switch r {
+	case '.', '!', '?', ';', ',', ':':
+		return true
+	}
+	return false
+}
+
+func isCloser(r rune) bool {
+	switch r {
+	case '"', '\'', ')', ']', '」', '』', '”', '’', '）', '》', '】':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package streaming_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+	"github.com/barkingdog-ai/azure-tts/streaming"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSegmenter(t *testing.T) {
+	tests := []struct {
+		name      string
+		deltas    []string
+		minLength int
+		want      [][]string // segments after each delta, then after Flush
+	}{
+		{
+			name:   "chinese sentences and clauses",
+			deltas: []string{"今天天氣很好", "，我們去公園散步吧。", "你覺得", "呢？"},
+			want:   [][]string{nil, {"今天天氣很好，", "我們去公園散步吧。"}, nil, {"你覺得呢？"}, nil},
+		},
+		{
+			name:      "short segments are joined",
+			deltas:    []string{"好。謝謝！今天天氣很好。"},
+			minLength: 6,
+			want:      [][]string{{"好。謝謝！今天天氣很好。"}, nil},
+		},
+		{
+			name:   "latin marks need following space",
+			deltas: []string{"Pi is 3.", "14 today.", " Visit example.com now"},
+			want:   [][]string{nil, nil, {"Pi is 3.14 today."}, {"Visit example.com now"}},
+		},
+		{
+			name:      "closing quotes stay with the sentence",
+			deltas:    []string{"他說：「我明天會到。」然後", "就走了。"},
+			minLength: 4,
+			want:      [][]string{{"他說：「我明天會到。」"}, {"然後就走了。"}, nil},
+		},
+		{
+			name:   "newline ends a segment",
+			deltas: []string{"第一行沒有標點符號\n第二行"},
+			want:   [][]string{{"第一行沒有標點符號"}, {"第二行"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minLength := tt.minLength
+			if minLength == 0 {
+				minLength = 2
+			}
+			s := streaming.NewSegmenter(minLength)
+			var got [][]string
+			for _, delta := range tt.deltas {
+				got = append(got, s.Write(delta))
+			}
+			got = append(got, s.Flush())
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// fakeSynthesizer returns "[text]" as audio, slower for earlier segments so they finish out of order.
+type fakeSynthesizer struct {
+	mu      sync.Mutex
+	active  int
+	maxSeen int
+	fail    string
+}
+
+func (s *fakeSynthesizer) TextToSpeechStream(ctx context.Context, request *model.TextToSpeechRequest) (io.ReadCloser, error) {
+	s.mu.Lock()
+	s.active++
+	s.maxSeen = max(s.maxSeen, s.active)
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.active--
+		s.mu.Unlock()
+	}()
+
+	if request.SpeechText == s.fail {
+		return nil, errors.New("synthesis failed")
+	}
+	delay := 30 * time.Millisecond
+	if strings.Contains(request.SpeechText, "三") {
+		delay = 0
+	}
+	select {
+	case <-time.After(delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return io.NopCloser(strings.NewReader("[" + request.VoiceName + ":" + request.SpeechText + "]")), nil
+}
+
+func TestIncrementalSynthesizer(t *testing.T) {
+	synth := &fakeSynthesizer{}
+	s := streaming.NewIncrementalSynthesizer(context.Background(), synth,
+		&model.TextToSpeechRequest{VoiceName: "v"}, streaming.WithMinLength(2), streaming.WithConcurrency(3))
+
+	go func() {
+		for _, delta := range []string{"第一", "句。第二句。", "第三句。第四", "句"} {
+			_, _ = io.WriteString(s, delta)
+		}
+		_ = s.Close()
+	}()
+	audio, err := io.ReadAll(s)
+	require.NoError(t, err)
+	assert.Equal(t, "[v:第一句。][v:第二句。][v:第三句。][v:第四句]", string(audio))
+	assert.LessOrEqual(t, synth.maxSeen, 3)
+
+	_, err = io.WriteString(s, "more")
+	assert.ErrorIs(t, err, streaming.ErrClosed)
+}
+
+func TestIncrementalSynthesizerError(t *testing.T) {
+	synth := &fakeSynthesizer{fail: "第二句。"}
+	s := streaming.NewIncrementalSynthesizer(context.Background(), synth, &model.TextToSpeechRequest{},
+		streaming.WithMinLength(2))
+	_, _ = io.WriteString(s, "第一句話說完了。第二句。第三句話說完了。")
+	_ = s.Close()
+
+	audio, err := io.ReadAll(s)
+	assert.EqualError(t, err, "synthesis failed")
+	assert.Equal(t, "[:第一句話說完了。]", string(audio))
+}
+
+func TestIncrementalSynthesizerCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := streaming.NewIncrementalSynthesizer(ctx, &fakeSynthesizer{}, &model.TextToSpeechRequest{})
+	_, _ = io.WriteString(s, "第一句話說完了。")
+	cancel()
+
+	_, err := io.ReadAll(s)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -1,10 +1,15 @@
 package azuretts
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/barkingdog-ai/azure-tts/model"
+	"gopkg.in/yaml.v3"
 )
 
 // 預定義的 TTS 風格
@@ -16,31 +21,29 @@ const (
 )
 
 // TTS 風格配置
-type StyleConfig struct {
-	Name        string
-	Style       string
-	StyleDegree string
-	Description string
-}
+type StyleConfig = model.StyleProfile
 
-// 預定義風格配置
+// 預定義風格配置，Fallbacks 會在聲音不支援主要風格時依序嘗試
 var PredefinedStyles = map[string]StyleConfig{
 	"enthusiastic": {
 		Name:        "enthusiastic",
 		Style:       StyleCheerful,
 		StyleDegree: "2",
+		Fallbacks:   []string{StyleFriendly},
 		Description: "活潑熱情客服，適合第一時間接聽",
 	},
 	"warm": {
 		Name:        "warm",
 		Style:       StyleFriendly,
 		StyleDegree: "1",
+		Fallbacks:   []string{StyleChat},
 		Description: "親切溫暖客服，給客戶安心感",
 	},
 	"professional": {
 		Name:        "professional",
 		Style:       StyleChat,
 		StyleDegree: "1",
+		Fallbacks:   []string{StyleFriendly},
 		Description: "正式又保持活潑，適合企業客服",
 	},
 	"calm": {
@@ -51,6 +54,73 @@ var PredefinedStyles = map[string]StyleConfig{
 	},
 }
 
+// 保護 PredefinedStyles 的執行期註冊
+var stylesMu sync.RWMutex
+
+// 註冊（或覆蓋）一個風格配置
+func RegisterStyleProfile(profile model.StyleProfile) error {
+	if profile.Name == "" {
+		return fmt.Errorf("style profile name is required")
+	}
+	if profile.Style == "" && profile.Role == "" {
+		return fmt.Errorf("style profile %s: style or role is required", profile.Name)
+	}
+
+	stylesMu.Lock()
+	defer stylesMu.Unlock()
+	PredefinedStyles[profile.Name] = profile
+	return nil
+}
+
+// 從 JSON 或 YAML 檔案載入並註冊風格配置，檔案內容為風格配置的陣列
+func LoadStyleProfiles(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading style profiles: %w", err)
+	}
+
+	profiles, err := ParseStyleProfiles(data, filepath.Ext(path))
+	if err != nil {
+		return fmt.Errorf("parsing style profiles %s: %w", path, err)
+	}
+	for _, profile := range profiles {
+		if err := RegisterStyleProfile(profile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 解析風格配置，format 為副檔名（.json、.yaml 或 .yml）
+func ParseStyleProfiles(data []byte, format string) ([]model.StyleProfile, error) {
+	var profiles []model.StyleProfile
+	switch strings.ToLower(strings.TrimPrefix(format, ".")) {
+	case "json":
+		if err := json.Unmarshal(data, &profiles); err != nil {
+			return nil, err
+		}
+	case "yaml", "yml":
+		if err := yaml.Unmarshal(data, &profiles); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported style profile format: %q", format)
+	}
+	return profiles, nil
+}
+
+// 從風格名稱獲取完整的風格配置，於合成時依聲音支援的風格解析
+func GetStyleProfile(styleName string) (*model.StyleProfile, error) {
+	stylesMu.RLock()
+	config, exists := PredefinedStyles[styleName]
+	stylesMu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("unknown style: %s. Available styles: %s",
+			styleName, strings.Join(getAvailableStyleNames(), ", "))
+	}
+	return &config, nil
+}
+
 // 創建帶風格的 SSML 文本
 func CreateStyledSSML(text, voiceName string, style *model.TTSStyle, locale string) string {
 	if style == nil {
@@ -74,20 +144,22 @@ func CreateStyledSSML(text, voiceName string, style *model.TTSStyle, locale stri
 
 // 從預定義風格名稱獲取風格配置
 func GetStyleFromName(styleName string) (*model.TTSStyle, error) {
-	config, exists := PredefinedStyles[styleName]
-	if !exists {
-		return nil, fmt.Errorf("unknown style: %s. Available styles: %s",
-			styleName, strings.Join(getAvailableStyleNames(), ", "))
+	config, err := GetStyleProfile(styleName)
+	if err != nil {
+		return nil, err
 	}
 
 	return &model.TTSStyle{
 		Style:       config.Style,
 		StyleDegree: config.StyleDegree,
+		Role:        config.Role,
 	}, nil
 }
 
 // 獲取所有可用風格名稱
 func getAvailableStyleNames() []string {
+	stylesMu.RLock()
+	defer stylesMu.RUnlock()
 	names := make([]string, 0, len(PredefinedStyles))
 	for name := range PredefinedStyles {
 		names = append(names, name)
@@ -97,6 +169,8 @@ func getAvailableStyleNames() []string {
 
 // 獲取風格描述
 func GetStyleDescription(styleName string) string {
+	stylesMu.RLock()
+	defer stylesMu.RUnlock()
 	if config, exists := PredefinedStyles[styleName]; exists {
 		return config.Description
 	}
@@ -105,6 +179,8 @@ func GetStyleDescription(styleName string) string {
 
 // 列出所有可用風格
 func ListAvailableStyles() map[string]string {
+	stylesMu.RLock()
+	defer stylesMu.RUnlock()
 	styles := make(map[string]string)
 	for name, config := range PredefinedStyles {
 		styles[name] = config.Description
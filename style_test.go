@@ -0,0 +1,86 @@
+package azuretts_test
+
+import (
+	"testing"
+
+	tts "github.com/barkingdog-ai/azure-tts"
+	"github.com/barkingdog-ai/azure-tts/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStyleProfiles(t *testing.T) {
+	data := []byte(`
+- name: upbeat
+  description: 輕快
+  style: excited
+  style_degree: "1.5"
+  role: YoungAdultFemale
+  fallbacks: [cheerful, friendly]
+  prosody:
+    rate: "+10%"
+    contour: ["(0%,+20Hz)", "(60%,-2st)"]
+  voices:
+    zh-TW-YunJheNeural:
+      role: YoungAdultMale
+`)
+	profiles, err := tts.ParseStyleProfiles(data, ".yaml")
+	assert.NoError(t, err)
+	assert.Len(t, profiles, 1)
+	assert.Equal(t, []string{"cheerful", "friendly"}, profiles[0].Fallbacks)
+	assert.Equal(t, "YoungAdultMale", profiles[0].Voices["zh-TW-YunJheNeural"].Role)
+
+	previous, registered := tts.PredefinedStyles["upbeat"]
+	t.Cleanup(func() {
+		if registered {
+			tts.PredefinedStyles["upbeat"] = previous
+		} else {
+			delete(tts.PredefinedStyles, "upbeat")
+		}
+	})
+	assert.NoError(t, tts.RegisterStyleProfile(profiles[0]))
+	profile, err := tts.GetStyleProfile("upbeat")
+	assert.NoError(t, err)
+	assert.Equal(t, model.Percent(10), profile.Prosody.Rate)
+	assert.Equal(t, []model.ContourPoint{{Position: 0, Pitch: model.RelativeHertz(20)}, {Position: 60, Pitch: model.Semitones(-2)}},
+		profile.Prosody.Contour)
+
+	_, err = tts.ParseStyleProfiles(data, ".ini")
+	assert.Error(t, err)
+}
+
+func TestStyleProfileResolve(t *testing.T) {
+	profile, err := tts.GetStyleProfile("enthusiastic")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		voice *model.VoiceListResponse
+		want  *model.TTSStyle
+	}{
+		{
+			name: "unknown catalog uses primary style",
+			want: &model.TTSStyle{Style: tts.StyleCheerful, StyleDegree: "2"},
+		},
+		{
+			name:  "supported primary style",
+			voice: &model.VoiceListResponse{ShortName: "en-US-JennyNeural", StyleList: []string{"cheerful", "sad"}},
+			want:  &model.TTSStyle{Style: tts.StyleCheerful, StyleDegree: "2"},
+		},
+		{
+			name:  "falls back to friendly",
+			voice: &model.VoiceListResponse{ShortName: "en-US-AriaNeural", StyleList: []string{"friendly"}},
+			want:  &model.TTSStyle{Style: tts.StyleFriendly, StyleDegree: "2"},
+		},
+		{
+			name:  "no supported style",
+			voice: &model.VoiceListResponse{ShortName: "zh-TW-HsiaoChenNeural"},
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, profile.Resolve(tt.voice))
+		})
+	}
+}
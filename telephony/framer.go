@@ -0,0 +1,208 @@
+package telephony
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// Option configures a Framer.
+type Option func(*Framer)
+
+// WithFrameDuration sets the duration of a frame, FrameDuration by default. It is rounded down to a
+// whole number of milliseconds.
+func WithFrameDuration(d time.Duration) Option {
+	return func(f *Framer) {
+		if ms := int(d / time.Millisecond); ms > 0 {
+			f.duration = time.Duration(ms) * time.Millisecond
+			f.size = SampleRate * ms / 1000
+		}
+	}
+}
+
+// WithoutPacing releases frames as soon as they are complete instead of in real time, e.g. to write
+// them to a file. Frames are then only filled with silence at the end of the speech.
+func WithoutPacing() Option {
+	return func(f *Framer) {
+		f.paced = false
+	}
+}
+
+// Framer splits G.711 audio into fixed-duration frames.
+type Framer struct {
+	src      io.Reader
+	codec    Codec
+	duration time.Duration
+	size     int
+	paced    bool
+
+	chunks  chan []byte
+	readErr error // set before chunks is closed
+	stop    chan struct{}
+	once    sync.Once
+
+	pending   []byte
+	eof       bool
+	start     time.Time
+	frames    int
+	underruns int
+}
+
+// NewFramer returns a Framer reading codec encoded audio from r. It starts reading r in the background.
+func NewFramer(r io.Reader, codec Codec, options ...Option) *Framer {
+	f := &Framer{
+		src:      r,
+		codec:    codec,
+		duration: FrameDuration,
+		size:     FrameSize,
+		paced:    true,
+		chunks:   make(chan []byte, 16),
+		stop:     make(chan struct{}),
+	}
+	for _, o := range options {
+		o(f)
+	}
+	go f.read()
+	return f
+}
+
+func (f *Framer) read() {
+	defer close(f.chunks)
+	for {
+		buf := make([]byte, 4096)
+		n, err := f.src.Read(buf)
+		if n > 0 {
+			select {
+			case f.chunks <- buf[:n]:
+			case <-f.stop:
+				return
+			}
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				f.readErr = err
+			}
+			return
+		}
+	}
+}
+
+// Codec returns the codec of the frames.
+func (f *Framer) Codec() Codec {
+	return f.codec
+}
+
+// Duration returns the duration of a frame.
+func (f *Framer) Duration() time.Duration {
+	return f.duration
+}
+
+// Underruns returns how many frames were filled with silence because the audio arrived too late.
+func (f *Framer) Underruns() int {
+	return f.underruns
+}
+
+// Next returns the next frame. When paced, the first call waits for the first audio and later calls
+// wait until their frame is due; a frame whose audio is not complete by then is filled with
+// silence. The last frame is filled with silence as well. Next returns io.EOF after the last frame
+// and the error of the stream if reading it failed.
+func (f *Framer) Next(ctx context.Context) ([]byte, error) {
+	if f.frames == 0 {
+		// the first frame waits for audio, so latency of the service isn't sent as silence
+		if err := f.fill(ctx, 1); err != nil {
+			return nil, err
+		}
+		f.start = time.Now()
+	}
+	if f.paced {
+		if err := f.waitUntil(ctx, f.start.Add(time.Duration(f.frames)*f.duration)); err != nil {
+			return nil, err
+		}
+		f.drain()
+	} else if err := f.fill(ctx, f.size); err != nil {
+		return nil, err
+	}
+
+	if len(f.pending) == 0 && f.eof {
+		if f.readErr != nil {
+			return nil, f.readErr
+		}
+		return nil, io.EOF
+	}
+
+	frame := make([]byte, f.size)
+	n := copy(frame, f.pending)
+	f.pending = f.pending[n:]
+	if n < f.size {
+		for i := n; i < f.size; i++ {
+			frame[i] = f.codec.Silence()
+		}
+		if !f.eof {
+			f.underruns++
+		}
+	}
+	f.frames++
+	return frame, nil
+}
+
+// fill waits until n bytes are pending or the stream ended.
+func (f *Framer) fill(ctx context.Context, n int) error {
+	for len(f.pending) < n && !f.eof {
+		select {
+		case chunk, ok := <-f.chunks:
+			f.add(chunk, ok)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// drain takes the audio that arrived so far.
+func (f *Framer) drain() {
+	for len(f.pending) < f.size && !f.eof {
+		select {
+		case chunk, ok := <-f.chunks:
+			f.add(chunk, ok)
+		default:
+			return
+		}
+	}
+}
+
+func (f *Framer) add(chunk []byte, ok bool) {
+	if !ok {
+		f.eof = true
+		return
+	}
+	f.pending = append(f.pending, chunk...)
+}
+
+func (f *Framer) waitUntil(ctx context.Context, at time.Time) error {
+	d := time.Until(at)
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops reading and closes the stream when it is an io.Closer.
+func (f *Framer) Close() error {
+	var err error
+	f.once.Do(func() {
+		close(f.stop)
+		if c, ok := f.src.(io.Closer); ok {
+			err = c.Close()
+		}
+	})
+	return err
+}
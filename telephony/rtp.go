@@ -0,0 +1,58 @@
+package telephony
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+)
+
+// rtpHeaderSize is the size of an RTP header without CSRC list or extension.
+const rtpHeaderSize = 12
+
+// Packetizer wraps frames in RTP packets, see RFC 3550.
+type Packetizer struct {
+	PayloadType uint8
+	SSRC        uint32
+	// Sequence and Timestamp are those of the next packet.
+	Sequence  uint16
+	Timestamp uint32
+
+	started bool
+}
+
+// NewPacketizer returns a Packetizer for codec frames sent by the source ssrc, starting at a random
+// sequence number and timestamp as RFC 3550 recommends.
+func NewPacketizer(codec Codec, ssrc uint32) *Packetizer {
+	var b [6]byte
+	_, _ = rand.Read(b[:])
+	return &Packetizer{
+		PayloadType: codec.PayloadType(),
+		SSRC:        ssrc,
+		Sequence:    binary.BigEndian.Uint16(b[:2]),
+		Timestamp:   binary.BigEndian.Uint32(b[2:]),
+	}
+}
+
+// Packet returns frame as the payload of the next RTP packet. The marker bit is set on the first
+// packet, the start of a talkspurt. The timestamp advances by one per G.711 sample.
+func (p *Packetizer) Packet(frame []byte) []byte {
+	packet := make([]byte, rtpHeaderSize+len(frame))
+	packet[0] = 2 << 6 // version 2, no padding, extension or CSRCs
+	packet[1] = p.PayloadType & 0x7F
+	if !p.started {
+		packet[1] |= 0x80
+		p.started = true
+	}
+	binary.BigEndian.PutUint16(packet[2:], p.Sequence)
+	binary.BigEndian.PutUint32(packet[4:], p.Timestamp)
+	binary.BigEndian.PutUint32(packet[8:], p.SSRC)
+	copy(packet[rtpHeaderSize:], frame)
+
+	p.Sequence++
+	p.Timestamp += uint32(len(frame))
+	return packet
+}
+
+// Mark sets the marker bit on the next packet, e.g. when speech resumes after a pause.
+func (p *Packetizer) Mark() {
+	p.started = false
+}
@@ -0,0 +1,94 @@
+// Package telephony streams synthesized speech as G.711 frames for telephony platforms such as
+// Asterisk or FreeSWITCH:
+//
+//	framer, err := telephony.Synthesize(ctx, client, request, telephony.PCMU)
+//	defer framer.Close()
+//	rtp := telephony.NewPacketizer(telephony.PCMU, ssrc)
+//	for {
+//		frame, err := framer.Next(ctx)
+//		if err != nil {
+//			break // io.EOF at the end of the speech
+//		}
+//		conn.Write(rtp.Packet(frame))
+//	}
+//
+// Frames are released in real time, one every frame duration, and filled with silence when the
+// service falls behind, so the receiving jitter buffer never runs dry.
+package telephony
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+)
+
+// Codec is a G.711 variant.
+type Codec int
+
+const (
+	// PCMU is G.711 mu-law, used in North America and Japan.
+	PCMU Codec = iota
+	// PCMA is G.711 A-law, used in most other countries.
+	PCMA
+)
+
+// SampleRate is the sample rate of G.711 audio. Every sample is one byte.
+const SampleRate = 8000
+
+// FrameDuration is the default duration of a frame, the usual RTP packetization time.
+const FrameDuration = 20 * time.Millisecond
+
+// FrameSize is the size of a frame of FrameDuration.
+const FrameSize = SampleRate * int(FrameDuration/time.Millisecond) / 1000
+
+func (c Codec) String() string {
+	if c == PCMA {
+		return "PCMA"
+	}
+	return "PCMU"
+}
+
+// Format returns the headerless output format to request the codec from the service in.
+func (c Codec) Format() model.AudioOutput {
+	if c == PCMA {
+		return model.AudioRAW8Bit8kHzMonoAlaw
+	}
+	return model.AudioRAW8Bit8kHzMonoMulaw
+}
+
+// PayloadType returns the static RTP payload type of the codec, see RFC 3551.
+func (c Codec) PayloadType() uint8 {
+	if c == PCMA {
+		return 8
+	}
+	return 0
+}
+
+// Silence returns the byte encoding a zero sample.
+func (c Codec) Silence() byte {
+	if c == PCMA {
+		return 0xD5
+	}
+	return 0xFF
+}
+
+// Synthesizer is implemented by api.AzureTTSClient.
+type Synthesizer interface {
+	TextToSpeechStream(ctx context.Context, request *model.TextToSpeechRequest) (io.ReadCloser, error)
+}
+
+// Synthesize requests the speech of request encoded with codec and returns a Framer over it. The
+// AudioOutput of request is ignored. Closing the Framer closes the stream.
+func Synthesize(ctx context.Context, s Synthesizer, request *model.TextToSpeechRequest, codec Codec,
+	options ...Option,
+) (*Framer, error) {
+	req := *request
+	req.AudioOutput = codec.Format()
+	stream, err := s.TextToSpeechStream(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	return NewFramer(stream, codec, options...), nil
+}
@@ -0,0 +1,137 @@
+package telephony_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/barkingdog-ai/azure-tts/model"
+	"github.com/barkingdog-ai/azure-tts/telephony"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func frames(t *testing.T, f *telephony.Framer) [][]byte {
+	t.Helper()
+	var out [][]byte
+	for {
+		frame, err := f.Next(context.Background())
+		if errors.Is(err, io.EOF) {
+			return out
+		}
+		require.NoError(t, err)
+		out = append(out, frame)
+	}
+}
+
+func TestFramer(t *testing.T) {
+	tests := []struct {
+		name      string
+		codec     telephony.Codec
+		audio     int
+		options   []telephony.Option
+		wantSizes int
+		wantCount int
+		wantFill  byte
+	}{
+		{name: "mu-law 20ms", codec: telephony.PCMU, audio: 400, wantSizes: 160, wantCount: 3, wantFill: 0xFF},
+		{name: "a-law 20ms", codec: telephony.PCMA, audio: 320, wantSizes: 160, wantCount: 2},
+		{name: "a-law 30ms", codec: telephony.PCMA, audio: 300, options: []telephony.Option{telephony.WithFrameDuration(30 * time.Millisecond)}, wantSizes: 240, wantCount: 2, wantFill: 0xD5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audio := bytes.Repeat([]byte{0x42}, tt.audio)
+			f := telephony.NewFramer(bytes.NewReader(audio), tt.codec, append(tt.options, telephony.WithoutPacing())...)
+			got := frames(t, f)
+			require.Len(t, got, tt.wantCount)
+			for _, frame := range got {
+				assert.Len(t, frame, tt.wantSizes)
+			}
+			last := got[len(got)-1]
+			if tt.wantFill != 0 {
+				assert.Equal(t, tt.wantFill, last[len(last)-1], "the last frame is filled with silence")
+			}
+			assert.Equal(t, 0, f.Underruns())
+		})
+	}
+}
+
+func TestFramerPacing(t *testing.T) {
+	r, w := io.Pipe()
+	f := telephony.NewFramer(r, telephony.PCMU)
+	defer f.Close()
+
+	go func() {
+		_, _ = w.Write(bytes.Repeat([]byte{0x42}, telephony.FrameSize))
+	}()
+	start := time.Now()
+	first, err := f.Next(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, byte(0x42), first[0])
+
+	// nothing arrives in time for the second frame
+	second, err := f.Next(context.Background())
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), telephony.FrameDuration)
+	assert.Equal(t, bytes.Repeat([]byte{0xFF}, telephony.FrameSize), second)
+	assert.Equal(t, 1, f.Underruns())
+
+	_ = w.Close()
+	_, err = f.Next(context.Background())
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestFramerStreamError(t *testing.T) {
+	r, w := io.Pipe()
+	f := telephony.NewFramer(r, telephony.PCMU, telephony.WithoutPacing())
+	boom := errors.New("connection reset")
+	_ = w.CloseWithError(boom)
+	_, err := f.Next(context.Background())
+	assert.ErrorIs(t, err, boom)
+}
+
+func TestPacketizer(t *testing.T) {
+	p := &telephony.Packetizer{PayloadType: telephony.PCMA.PayloadType(), SSRC: 0x01020304, Sequence: 65535, Timestamp: 1000}
+	frame := bytes.Repeat([]byte{0xD5}, telephony.FrameSize)
+
+	first := p.Packet(frame)
+	require.Len(t, first, 12+telephony.FrameSize)
+	assert.Equal(t, byte(0x80), first[0])
+	assert.Equal(t, byte(0x80|8), first[1], "marker set on the first packet")
+	assert.Equal(t, uint16(65535), binary.BigEndian.Uint16(first[2:]))
+	assert.Equal(t, uint32(1000), binary.BigEndian.Uint32(first[4:]))
+	assert.Equal(t, uint32(0x01020304), binary.BigEndian.Uint32(first[8:]))
+	assert.Equal(t, frame, first[12:])
+
+	second := p.Packet(frame)
+	assert.Equal(t, byte(8), second[1])
+	assert.Equal(t, uint16(0), binary.BigEndian.Uint16(second[2:]), "sequence wraps around")
+	assert.Equal(t, uint32(1160), binary.BigEndian.Uint32(second[4:]))
+
+	assert.Equal(t, uint8(0), telephony.NewPacketizer(telephony.PCMU, 1).PayloadType)
+}
+
+type fakeSynthesizer struct {
+	request *model.TextToSpeechRequest
+}
+
+func (s *fakeSynthesizer) TextToSpeechStream(_ context.Context, request *model.TextToSpeechRequest) (io.ReadCloser, error) {
+	s.request = request
+	return io.NopCloser(bytes.NewReader(make([]byte, telephony.FrameSize))), nil
+}
+
+func TestSynthesize(t *testing.T) {
+	s := &fakeSynthesizer{}
+	request := &model.TextToSpeechRequest{SpeechText: "你好", AudioOutput: model.Audio24khz96kbitrateMonoMp3}
+	f, err := telephony.Synthesize(context.Background(), s, request, telephony.PCMA, telephony.WithoutPacing())
+	require.NoError(t, err)
+	defer f.Close()
+
+	assert.Equal(t, model.AudioRAW8Bit8kHzMonoAlaw, s.request.AudioOutput)
+	assert.Equal(t, model.Audio24khz96kbitrateMonoMp3, request.AudioOutput, "the caller's request is unchanged")
+	assert.Len(t, frames(t, f), 1)
+}
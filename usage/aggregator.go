@@ -0,0 +1,90 @@
+package usage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Summary is the usage of one tenant in one billing category.
+type Summary struct {
+	Tenant     string `json:"tenant"`
+	Kind       Kind   `json:"kind"`
+	Requests   int64  `json:"requests"`
+	Characters int64  `json:"characters,omitempty"`
+	// AudioSeconds is the recognized audio in seconds.
+	AudioSeconds float64 `json:"audio_seconds,omitempty"`
+}
+
+// Reporter exposes aggregated usage for export to a billing system.
+type Reporter interface {
+	// Usage returns the usage recorded so far.
+	Usage() []Summary
+	// Flush returns the usage recorded so far and starts over, so each call reports a new period.
+	Flush() []Summary
+}
+
+type summaryKey struct {
+	tenant string
+	kind   Kind
+}
+
+// Aggregator is an in-memory Meter and Reporter summing usage per tenant and kind.
+type Aggregator struct {
+	mu     sync.Mutex
+	totals map[summaryKey]*Summary
+	audio  map[summaryKey]time.Duration
+}
+
+// NewAggregator returns an empty Aggregator.
+func NewAggregator() *Aggregator {
+	return &Aggregator{totals: make(map[summaryKey]*Summary), audio: make(map[summaryKey]time.Duration)}
+}
+
+func (a *Aggregator) Record(_ context.Context, r Record) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	key := summaryKey{tenant: r.Tenant, kind: r.Kind}
+	s, ok := a.totals[key]
+	if !ok {
+		s = &Summary{Tenant: r.Tenant, Kind: r.Kind}
+		a.totals[key] = s
+	}
+	s.Requests++
+	s.Characters += int64(r.Characters)
+	// durations are summed exactly and converted once, so many short calls don't accumulate rounding
+	a.audio[key] += r.AudioDuration
+}
+
+func (a *Aggregator) Usage() []Summary {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.snapshot()
+}
+
+func (a *Aggregator) Flush() []Summary {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	out := a.snapshot()
+	a.totals = make(map[summaryKey]*Summary)
+	a.audio = make(map[summaryKey]time.Duration)
+	return out
+}
+
+// snapshot returns the totals sorted by tenant and kind. a.mu must be held.
+func (a *Aggregator) snapshot() []Summary {
+	out := make([]Summary, 0, len(a.totals))
+	for key, s := range a.totals {
+		summary := *s
+		summary.AudioSeconds = a.audio[key].Seconds()
+		out = append(out, summary)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Tenant != out[j].Tenant {
+			return out[i].Tenant < out[j].Tenant
+		}
+		return out[i].Kind < out[j].Kind
+	})
+	return out
+}
@@ -0,0 +1,84 @@
+// Package usage accounts for the billable usage of the Azure speech service per tenant.
+//
+// Azure bills synthesis per character and recognition per second of audio. The client reports
+// every successful call to a Meter, tagged with the tenant stored in the context by WithTenant:
+//
+//	agg := usage.NewAggregator()
+//	client, _ := azuretts.NewClient(key, region, API.WithUsageMeter(agg))
+//	audio, _ := client.TextToSpeech(usage.WithTenant(ctx, "acme"), req)
+//	for _, s := range agg.Flush() { ... }
+package usage
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Kind is the billing category of a call.
+type Kind string
+
+const (
+	KindNeuralTTS   Kind = "tts_neural"
+	KindStandardTTS Kind = "tts_standard"
+	KindSTT         Kind = "stt"
+)
+
+// Record is the usage of a single call.
+type Record struct {
+	Tenant string
+	Kind   Kind
+	Voice  string
+	// Characters is the number of billable characters of a synthesis call.
+	Characters int
+	// AudioDuration is the recognized audio of a recognition call.
+	AudioDuration time.Duration
+	Time          time.Time
+}
+
+// Meter receives the usage of every call. Implementations must be safe for concurrent use.
+type Meter interface {
+	Record(ctx context.Context, r Record)
+}
+
+type tenantKey struct{}
+
+// WithTenant returns a context whose calls are accounted to tenant.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// Tenant returns the tenant stored in ctx by WithTenant, or "" when there is none.
+func Tenant(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// KindForVoice returns the synthesis billing category of voice. Voices are neural unless their name
+// says otherwise, Azure retired the standard voices.
+func KindForVoice(voice string) Kind {
+	if voice != "" && !strings.Contains(voice, "Neural") {
+		return KindStandardTTS
+	}
+	return KindNeuralTTS
+}
+
+// reBillingExcluded matches the SSML elements Azure does not bill.
+var reBillingExcluded = regexp.MustCompile(`</?(?:speak|voice)(?:\s[^>]*)?>`)
+
+// BillableCharacters counts the characters of an SSML document the way Azure bills them: every code
+// point of the document counts, including markup and white space, except the speak and voice tags.
+// Chinese characters, including kanji and hanja, count as two.
+func BillableCharacters(ssml string) int {
+	n := 0
+	for _, r := range reBillingExcluded.ReplaceAllString(ssml, "") {
+		if unicode.Is(unicode.Han, r) {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,58 @@
+package usage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/barkingdog-ai/azure-tts/usage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBillableCharacters(t *testing.T) {
+	tests := []struct {
+		name string
+		ssml string
+		want int
+	}{
+		{name: "plain", ssml: "hello", want: 5},
+		{name: "han counts double", ssml: "你好 ok", want: 7},
+		{name: "kana and hangul count once", ssml: "かな한글", want: 4},
+		{
+			name: "speak and voice tags excluded",
+			ssml: `<speak version='1.0' xml:lang='zh-TW'><voice xml:lang='zh-TW' xml:gender='Female' name='zh-TW-HsiaoChenNeural'>你好</voice></speak>`,
+			want: 4,
+		},
+		{
+			name: "other markup billed",
+			ssml: `<speak version="1.0"><voice name="a"><break time="500ms"/>hi</voice></speak>`,
+			want: len(`<break time="500ms"/>hi`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, usage.BillableCharacters(tt.ssml))
+		})
+	}
+}
+
+func TestAggregator(t *testing.T) {
+	agg := usage.NewAggregator()
+	ctx := usage.WithTenant(context.Background(), "acme")
+	assert.Equal(t, "acme", usage.Tenant(ctx))
+	assert.Equal(t, "", usage.Tenant(context.Background()))
+
+	agg.Record(ctx, usage.Record{Tenant: "acme", Kind: usage.KindNeuralTTS, Characters: 10})
+	agg.Record(ctx, usage.Record{Tenant: "acme", Kind: usage.KindNeuralTTS, Characters: 5})
+	agg.Record(ctx, usage.Record{Tenant: "acme", Kind: usage.KindSTT, AudioDuration: 1500 * time.Millisecond})
+	agg.Record(ctx, usage.Record{Tenant: "beta", Kind: usage.KindStandardTTS, Characters: 3})
+
+	want := []usage.Summary{
+		{Tenant: "acme", Kind: usage.KindSTT, Requests: 1, AudioSeconds: 1.5},
+		{Tenant: "acme", Kind: usage.KindNeuralTTS, Requests: 2, Characters: 15},
+		{Tenant: "beta", Kind: usage.KindStandardTTS, Requests: 1, Characters: 3},
+	}
+	assert.Equal(t, want, agg.Usage())
+	assert.Equal(t, want, agg.Flush())
+	assert.Empty(t, agg.Usage())
+}